@@ -1,44 +1,195 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
 
 	"github.com/niko-2609/tracker-expense/database"
+	attachmentModels "github.com/niko-2609/tracker-expense/models/attachment"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/health"
 	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	bodylimit "github.com/niko-2609/tracker-expense/pkg/middleware/bodylimit"
+	"github.com/niko-2609/tracker-expense/pkg/openapi"
 	"github.com/niko-2609/tracker-expense/pkg/router"
+	"github.com/niko-2609/tracker-expense/pkg/storage"
+	"github.com/niko-2609/tracker-expense/pkg/suggest"
+	"github.com/niko-2609/tracker-expense/pkg/tracing"
 )
 
+// Time given to in-flight requests and background jobs after SIGTERM,
+// overridden by `SHUTDOWN_TIMEOUT` (a Go duration)
+const defaultShutdownTimeout = 30 * time.Second
+
 func main() {
-	// New fiber app instance
-	app := fiber.New()
+	// JSON logs on stdout, the level is set by `LOG_LEVEL`
+	logs.Setup()
+
+	// Export traces, see tracing.Setup for the `OTEL_*` settings
+	shutdownTracing, err := tracing.Setup(context.Background())
+	if err != nil {
+		exit(err)
+	}
+
+	// Connect to database before any route can be served
+	if err := database.ConnectDB(); err != nil {
+		exit(err)
+	}
+	if sqlDB, err := database.DB.DB(); err == nil {
+		metrics.RegisterDB(sqlDB)
+	}
+
+	// Connect blob storage for receipts
+	if err := storage.Connect(); err != nil {
+		exit(err)
+	}
+
+	// Train category suggestion models in the background
+	suggest.StartTrainer(2)
+
+	// New fiber app instance. Bodies over the default limit are streamed
+	// and read by bodylimit, only receipt uploads may be that large.
+	// Errors returned by handlers become `{status, code, message}` responses.
+	config := fiber.Config{
+		BodyLimit:                    fiber.DefaultBodyLimit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		ErrorHandler:                 apierror.Handler,
+	}
+	trustProxies(&config)
+	app := fiber.New(config)
+
+	// Probes come before the middleware, so they are not logged, counted or traced
+	app.Get("/healthz", health.LivenessHandler)
+	app.Get("/readyz", health.ReadinessHandler)
 
 	// Initialize requestid to track requests
 	app.Use(requestid.New())
 
-	// Log remote IP, port and request id.
+	// Span for every request, continuing the caller's trace
+	app.Use(tracing.Middleware)
+
+	// Log every request with its id, user, route, status and latency.
 	app.Use(logs.CustomLogger)
 
+	// Count requests and their latency per route
+	app.Use(metrics.Middleware)
+
+	// Write error responses here, the middleware above reads their status
+	app.Use(apierror.Middleware)
+
+	// Bodies up to the default limit, receipt uploads up to their own
+	app.Use(bodylimit.New(fiber.DefaultBodyLimit, bodylimit.Route{
+		Method: fiber.MethodPost,
+		Path:   "/api/transaction/:id/attachments",
+		Limit:  attachmentModels.MaxAttachmentSize + 1<<20,
+	}))
+
 	// CORS settings
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Authorization, Accept",
-		AllowMethods: "GET, POST, PUT, DELETE",
+		AllowOrigins:  "*",
+		AllowHeaders:  "Origin, Content-Type, Authorization, Accept, Idempotency-Key, If-Match, If-None-Match, traceparent, tracestate, baggage",
+		ExposeHeaders: "ETag, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Retry-After",
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE",
 	}))
 
 	// Setup routing
 	router.SetupRoutes(app)
 
-	// Connect to database
-	err := database.ConnectDB()
+	// Prometheus metrics on `METRICS_ADDR` or behind `METRICS_TOKEN`
+	metrics.Serve(app)
+
+	// Every route must be in the OpenAPI spec, the openapi tests enforce it
+	if err := openapi.CheckRoutes(app); err != nil {
+		slog.Warn("OpenAPI spec is out of date", "error", err)
+	}
+
+	// Start server, until it fails or SIGINT or SIGTERM arrives
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- app.Listen(":3000")
+	}()
+
+	select {
+	case err = <-listenErr:
+		slog.Error("Server stopped", "error", err)
+	case <-ctx.Done():
+		slog.Info("Shutting down")
+	}
+	stop()
+
+	if shutdownErr := shutdown(app, shutdownTracing); shutdownErr != nil {
+		slog.Error("Shutdown incomplete", "error", shutdownErr)
+		os.Exit(1)
+	}
 	if err != nil {
-		log.Fatalf("Exiting service, %s", err)
+		os.Exit(1)
 	}
+}
 
-	// Start server
-	app.Listen(":3000")
+// Stop taking requests, let in-flight requests and queued background jobs
+// finish within the shutdown timeout, then flush traces and close the DB pool
+func shutdown(app *fiber.App, shutdownTracing func(context.Context) error) error {
+	timeout := defaultShutdownTimeout
+	if value := os.Getenv("SHUTDOWN_TIMEOUT"); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			timeout = parsed
+		} else {
+			slog.Warn("Invalid SHUTDOWN_TIMEOUT, using the default", "value", value, "default", defaultShutdownTimeout.String())
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	health.Drain()
+	return errors.Join(
+		app.ShutdownWithContext(ctx),
+		metrics.Shutdown(ctx),
+		suggest.StopTrainer(ctx),
+		shutdownTracing(ctx),
+		database.Close(),
+	)
+}
+
+// Behind a reverse proxy `TRUSTED_PROXIES` lists its addresses or CIDR
+// ranges, comma separated. Requests from them are read as coming from the
+// first address in `PROXY_HEADER` (`X-Forwarded-For` by default), which logs
+// and the per IP rate limits use. Without it every client behind the proxy
+// shares the proxy's address. The proxy must overwrite the header, an
+// address the client sent itself would be trusted otherwise.
+func trustProxies(config *fiber.Config) {
+	value := os.Getenv("TRUSTED_PROXIES")
+	if value == "" {
+		return
+	}
+	for _, proxy := range strings.Split(value, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			config.TrustedProxies = append(config.TrustedProxies, proxy)
+		}
+	}
+	config.EnableTrustedProxyCheck = true
+	config.EnableIPValidation = true
+	config.ProxyHeader = os.Getenv("PROXY_HEADER")
+	if config.ProxyHeader == "" {
+		config.ProxyHeader = fiber.HeaderXForwardedFor
+	}
+}
 
+func exit(err error) {
+	slog.Error("Exiting service", "error", err)
+	os.Exit(1)
 }
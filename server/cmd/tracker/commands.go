@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/client"
+	"golang.org/x/term"
+)
+
+func runLogin(ctx context.Context, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	fs := newFlagSet("login")
+	server := fs.String("server", cfg.Server, "URL of the API")
+	email := fs.String("email", cfg.Email, "Email of the account")
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if *email == "" {
+		if *email, err = prompt("Email: "); err != nil {
+			return err
+		}
+	}
+	password, err := readPassword()
+	if err != nil {
+		return err
+	}
+
+	c := client.New(*server)
+	if err := c.Auth.Login(ctx, *email, password); err != nil {
+		var apiErr *client.APIError
+		if errors.As(err, &apiErr) && apiErr.Message != "" {
+			return fmt.Errorf("login failed: %s", apiErr.Message)
+		}
+		return err
+	}
+
+	cfg.Server, cfg.Email, cfg.Token = *server, *email, c.Auth.Token()
+	if err := cfg.save(); err != nil {
+		return err
+	}
+	fmt.Println("Logged in as", *email)
+	return nil
+}
+
+func runAdd(ctx context.Context, args []string) error {
+	fs := newFlagSet("add")
+	category := fs.String("category", "", "Category name or id, rules pick one when empty")
+	txnType := fs.String("type", transactionModels.TxnTypeExpense, "expense or income")
+	frequency := fs.String("frequency", "monthly", "daily, weekly, monthly, quarterly or yearly")
+	description := fs.String("description", "", "Longer description")
+	tags := fs.String("tags", "", "Comma separated tags")
+	account := fs.Uint("account", 0, "Account id, the first account when 0")
+	ledger := fs.Uint("ledger", 0, "Ledger id, the personal ledger when 0")
+	force := fs.Bool("force", false, "Add it even when it looks like a duplicate")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return errors.New("add needs an amount and a name")
+	}
+	amount, err := parseAmount(positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	req := &transactionModels.AddTransactionRequest{
+		Name:        positional[1],
+		Amount:      amount,
+		TxnType:     *txnType,
+		Frequency:   *frequency,
+		AccountID:   *account,
+		LedgerID:    *ledger,
+		Description: *description,
+		Tags:        splitList(*tags, ","),
+	}
+	if *category != "" {
+		if req.CategoryID, err = newCategoryResolver(c).resolve(ctx, *category, *txnType); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.Transactions.Add(ctx, req, *force); err != nil {
+		var apiErr *client.APIError
+		if errors.Is(err, client.ErrConflict) && errors.As(err, &apiErr) {
+			var candidates []transactionModels.Transaction
+			if apiErr.DecodeData(&candidates) == nil && len(candidates) > 0 {
+				return fmt.Errorf("looks like a duplicate of transaction %d, add it anyway with --force", candidates[0].ID)
+			}
+		}
+		return err
+	}
+	fmt.Printf("Added %s %s\n", req.Name, formatAmount(req.Amount))
+	return nil
+}
+
+func runList(ctx context.Context, args []string) error {
+	fs := newFlagSet("ls")
+	month := fs.String("month", "", "Only transactions of this month, YYYY-MM")
+	tags := fs.String("tags", "", "Comma separated tags, any of them must match")
+	allTags := fs.Bool("all-tags", false, "All tags must match")
+	ledger := fs.Uint("ledger", 0, "Only transactions of this ledger")
+	format := formatFlag(fs, formatTable)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	return listTransactions(ctx, *month, &client.ListOptions{Tags: splitList(*tags, ","), MatchAll: *allTags, LedgerID: *ledger}, *format)
+}
+
+func runExport(ctx context.Context, args []string) error {
+	fs := newFlagSet("export")
+	month := fs.String("month", "", "Only transactions of this month, YYYY-MM")
+	ledger := fs.Uint("ledger", 0, "Only transactions of this ledger")
+	format := formatFlag(fs, formatCSV)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	return listTransactions(ctx, *month, &client.ListOptions{LedgerID: *ledger}, *format)
+}
+
+func listTransactions(ctx context.Context, month string, opts *client.ListOptions, format string) error {
+	if err := checkFormat(format); err != nil {
+		return err
+	}
+	var from, to time.Time
+	if month != "" {
+		start, err := time.ParseInLocation("2006-01", month, time.Local)
+		if err != nil {
+			return fmt.Errorf("invalid month %q, use YYYY-MM", month)
+		}
+		from, to = start, start.AddDate(0, 1, 0)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	transactions, err := c.Transactions.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+	if month != "" {
+		filtered := transactions[:0]
+		for _, transaction := range transactions {
+			if !transaction.TxnDate.Before(from) && transaction.TxnDate.Before(to) {
+				filtered = append(filtered, transaction)
+			}
+		}
+		transactions = filtered
+	}
+
+	categoryNames := map[uint]string{}
+	if format != formatJSON {
+		if categoryNames, err = newCategoryResolver(c).names(ctx); err != nil {
+			return err
+		}
+	}
+	return writeOutput(os.Stdout, format, transactionTable(transactions, categoryNames), transactions)
+}
+
+func runEdit(ctx context.Context, args []string) error {
+	fs := newFlagSet("edit")
+	fs.String("name", "", "New name")
+	fs.String("amount", "", "New amount")
+	fs.String("category", "", "New category name or id")
+	fs.String("type", "", "expense or income")
+	fs.String("frequency", "", "daily, weekly, monthly, quarterly or yearly")
+	fs.String("description", "", "New description")
+	fs.String("tags", "", "Comma separated tags, replaces all tags, empty removes them")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("edit needs the id of a transaction")
+	}
+	id, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	// Only the flags that were given are changed
+	req := &transactionModels.UpdateTransactionRequest{}
+	var category string
+	var visitErr error
+	fs.Visit(func(f *flag.Flag) {
+		value := f.Value.String()
+		switch f.Name {
+		case "name":
+			req.Name = &value
+		case "amount":
+			amount, err := parseAmount(value)
+			if err != nil {
+				visitErr = err
+			}
+			req.Amount = &amount
+		case "category":
+			category = value
+		case "type":
+			req.TxnType = &value
+		case "frequency":
+			req.Frequency = &value
+		case "description":
+			req.Description = &value
+		case "tags":
+			tags := splitList(value, ",")
+			if tags == nil {
+				tags = []string{}
+			}
+			req.Tags = &tags
+		}
+	})
+	if visitErr != nil {
+		return visitErr
+	}
+	if category != "" {
+		txnType := ""
+		if req.TxnType != nil {
+			txnType = *req.TxnType
+		}
+		categoryID, err := newCategoryResolver(c).resolve(ctx, category, txnType)
+		if err != nil {
+			return err
+		}
+		req.CategoryID = &categoryID
+	}
+
+	if _, err := c.Transactions.Update(ctx, id, req, ""); err != nil {
+		return err
+	}
+	fmt.Println("Updated transaction", id)
+	return nil
+}
+
+func runRemove(ctx context.Context, args []string) error {
+	fs := newFlagSet("rm")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return errors.New("rm needs the id of a transaction")
+	}
+	ids := make([]uint, 0, len(positional))
+	for _, arg := range positional {
+		id, err := parseID(arg)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := c.Transactions.Delete(ctx, id, ""); err != nil {
+			return fmt.Errorf("transaction %d: %s", id, describe(err))
+		}
+		fmt.Println("Deleted transaction", id)
+	}
+	return nil
+}
+
+func runSummary(ctx context.Context, args []string) error {
+	fs := newFlagSet("summary")
+	chart := fs.Bool("chart", false, "Draw the monthly net totals as a chart")
+	format := formatFlag(fs, formatTable)
+	if _, err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	metrics, err := c.Dashboard.Get(ctx)
+	if err != nil {
+		return err
+	}
+	summary, err := newSummary(metrics)
+	if err != nil {
+		return err
+	}
+
+	if *format != formatTable || !*chart {
+		return writeOutput(os.Stdout, *format, summary.table(), metrics)
+	}
+	if len(summary.months) == 0 {
+		fmt.Println("No transactions yet")
+		return nil
+	}
+	labels := make([]string, len(summary.months))
+	values := make([]float64, len(summary.months))
+	for i, month := range summary.months {
+		labels[i], values[i] = month.label, month.amount
+	}
+	writeChart(os.Stdout, labels, values)
+	return nil
+}
+
+// Prompt on stderr and read a line from stdin
+func prompt(label string) (string, error) {
+	fmt.Fprint(os.Stderr, label)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Password without echo on a terminal, a line from stdin otherwise
+func readPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return prompt("")
+	}
+	fmt.Fprint(os.Stderr, "Password: ")
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	return string(password), err
+}
+
+func parseAmount(value string) (float64, error) {
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil || amount <= 0 {
+		return 0, fmt.Errorf("invalid amount %q", value)
+	}
+	return amount, nil
+}
+
+func parseID(value string) (uint, error) {
+	id, err := strconv.ParseUint(value, 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("invalid id %q", value)
+	}
+	return uint(id), nil
+}
+
+// Trimmed non-empty items of a separated list, nil for none
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Looks up category ids by name, the list is fetched once
+type categoryResolver struct {
+	client     *client.Client
+	categories []transactionModels.CategorySummary
+}
+
+func newCategoryResolver(c *client.Client) *categoryResolver {
+	return &categoryResolver{client: c}
+}
+
+func (r *categoryResolver) load(ctx context.Context) error {
+	if r.categories != nil {
+		return nil
+	}
+	categories, err := r.client.Categories.List(ctx)
+	if err != nil {
+		return err
+	}
+	r.categories = append([]transactionModels.CategorySummary{}, categories...)
+	return nil
+}
+
+// Id of a category given by id or name. Names match case-insensitively,
+// a category of `txnType` wins when the name is used for both types.
+func (r *categoryResolver) resolve(ctx context.Context, value, txnType string) (uint, error) {
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil && id > 0 {
+		return uint(id), nil
+	}
+	if err := r.load(ctx); err != nil {
+		return 0, err
+	}
+
+	var match *transactionModels.CategorySummary
+	for i, category := range r.categories {
+		if !strings.EqualFold(category.Name, value) {
+			continue
+		}
+		if match == nil || category.Type == txnType {
+			match = &r.categories[i]
+		}
+	}
+	if match == nil {
+		return 0, fmt.Errorf("unknown category %q", value)
+	}
+	return match.ID, nil
+}
+
+func (r *categoryResolver) names(ctx context.Context) (map[uint]string, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	names := make(map[uint]string, len(r.categories))
+	for _, category := range r.categories {
+		names[category.ID] = category.Name
+	}
+	return names, nil
+}
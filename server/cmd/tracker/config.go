@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/niko-2609/tracker-expense/pkg/client"
+)
+
+const defaultServer = "http://localhost:3000"
+
+// Kept in `config.json` under the XDG config dir, readable by the user only.
+// The password is never stored, once the token expires log in again.
+type config struct {
+	Server string `json:"server"`
+	Email  string `json:"email,omitempty"`
+	Token  string `json:"token,omitempty"`
+}
+
+// `$XDG_CONFIG_HOME/tracker/config.json`, `~/.config` when unset
+func configPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "tracker", "config.json"), nil
+}
+
+// Saved config, the defaults when there is none yet
+func loadConfig() (*config, error) {
+	cfg := &config{Server: defaultServer}
+	path, err := configPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (cfg *config) save() error {
+	path, err := configPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// API client with the saved token, `TRACKER_SERVER` overrides the server
+func newClient() (*client.Client, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	server := cfg.Server
+	if env := os.Getenv("TRACKER_SERVER"); env != "" {
+		server = env
+	}
+	return client.New(server, client.WithToken(cfg.Token)), nil
+}
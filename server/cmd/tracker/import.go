@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Transactions sent per bulk request, the API accepts up to 100
+const importBatchSize = 100
+
+// Line of the CSV file and the transaction read from it
+type importRow struct {
+	line int
+	req  *transactionModels.AddTransactionRequest
+}
+
+// Reads the columns of `export`, `name` and `amount` are required. `id` is
+// ignored, rows without a `date` are dated today.
+func runImport(ctx context.Context, args []string) error {
+	fs := newFlagSet("import")
+	force := fs.Bool("force", false, "Add rows even when they look like duplicates")
+	ledger := fs.Uint("ledger", 0, "Ledger id, the personal ledger when 0")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return errors.New("import needs a CSV file")
+	}
+
+	file, err := os.Open(positional[0])
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	c, err := newClient()
+	if err != nil {
+		return err
+	}
+	rows, err := readImport(ctx, file, newCategoryResolver(c), *ledger)
+	if err != nil {
+		return err
+	}
+
+	added, failed := 0, 0
+	for start := 0; start < len(rows); start += importBatchSize {
+		batch := rows[start:min(start+importBatchSize, len(rows))]
+		bulkReq := &transactionModels.BulkTransactionRequest{
+			Mode:  transactionModels.BulkModeBestEffort,
+			Force: *force,
+		}
+		for _, row := range batch {
+			bulkReq.Operations = append(bulkReq.Operations, transactionModels.BulkOperation{
+				Op:     transactionModels.BulkOpCreate,
+				Create: row.req,
+			})
+		}
+
+		results, err := c.Transactions.Bulk(ctx, bulkReq)
+		if err != nil {
+			return fmt.Errorf("after %d added: %s", added, describe(err))
+		}
+		for _, result := range results {
+			if result.Status == "success" {
+				added++
+				continue
+			}
+			failed++
+			message := result.Message
+			if len(result.Duplicates) > 0 {
+				message = fmt.Sprintf("looks like a duplicate of transaction %d, import with --force to add it", result.Duplicates[0])
+			}
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", batch[result.Index].line, message)
+		}
+	}
+
+	fmt.Printf("Imported %d transactions", added)
+	if failed > 0 {
+		fmt.Printf(", %d failed", failed)
+	}
+	fmt.Println()
+	return nil
+}
+
+func readImport(ctx context.Context, r io.Reader, categories *categoryResolver, ledgerID uint) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"name", "amount"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("column %q is missing", name)
+		}
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		amount, err := parseAmount(field("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		date := field("date")
+		if date != "" {
+			if _, err := time.Parse(time.DateOnly, date); err != nil {
+				return nil, fmt.Errorf("line %d: date %q is not YYYY-MM-DD", line, date)
+			}
+		}
+		req := &transactionModels.AddTransactionRequest{
+			Name:        field("name"),
+			Amount:      amount,
+			TxnType:     field("type"),
+			Frequency:   field("frequency"),
+			LedgerID:    ledgerID,
+			Description: field("description"),
+			TxnDate:     date,
+			Tags:        splitList(field("tags"), ";"),
+		}
+		if req.TxnType == "" {
+			req.TxnType = transactionModels.TxnTypeExpense
+		}
+		if req.Frequency == "" {
+			req.Frequency = "monthly"
+		}
+		if category := field("category"); category != "" {
+			if req.CategoryID, err = categories.resolve(ctx, category, req.TxnType); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+		}
+		rows = append(rows, importRow{line: line, req: req})
+	}
+}
@@ -0,0 +1,131 @@
+// Command tracker is a command-line client for the tracker-expense API.
+//
+//	tracker login --server http://localhost:3000 --email me@example.com
+//	tracker add 12.50 "Coffee" --category food
+//	tracker ls --month 2026-10
+//	tracker summary --chart
+//
+// The server and token are kept in `$XDG_CONFIG_HOME/tracker/config.json`.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/niko-2609/tracker-expense/pkg/client"
+)
+
+// Subcommand, `args` are the arguments after its name
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(ctx context.Context, args []string) error
+}
+
+// Filled in `init`, the commands look up their own usage line here
+var commands []command
+
+func init() {
+	commands = []command{
+		{"login", "login [--server URL] [--email EMAIL]", "Log in and keep the token in the config file", runLogin},
+		{"add", "add AMOUNT NAME [--category NAME|ID] [--type expense|income] [--tags a,b]", "Add a transaction", runAdd},
+		{"ls", "ls [--month YYYY-MM] [--tags a,b] [--ledger ID] [-o table|json|csv]", "List transactions", runList},
+		{"edit", "edit ID [--name NAME] [--amount AMOUNT] [--category NAME|ID] [--tags a,b]", "Change fields of a transaction", runEdit},
+		{"rm", "rm ID...", "Delete transactions", runRemove},
+		{"import", "import FILE.csv [--force]", "Add the transactions of a CSV file", runImport},
+		{"export", "export [--month YYYY-MM] [-o csv|json]", "Write transactions to stdout", runExport},
+		{"summary", "summary [--chart] [-o table|json|csv]", "Show the dashboard", runSummary},
+	}
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	name, args := flag.Arg(0), flag.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(ctx, args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(2)
+			}
+			fmt.Fprintln(os.Stderr, "tracker:", describe(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "tracker: unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: tracker COMMAND [ARGS]")
+	fmt.Fprintln(os.Stderr)
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Run `tracker COMMAND -h` for the flags of a command.")
+}
+
+// Message for an error, with a hint for the common API failures
+func describe(err error) string {
+	var apiErr *client.APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+	switch {
+	case errors.Is(err, client.ErrUnauthorized):
+		return "not logged in or the session expired, run `tracker login`"
+	case apiErr.Message != "":
+		return apiErr.Message
+	}
+	return err.Error()
+}
+
+// Flag set of a subcommand, `-h` prints its usage line and flags
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		for _, cmd := range commands {
+			if cmd.name == name {
+				fmt.Fprintln(fs.Output(), "Usage: tracker", cmd.usage)
+			}
+		}
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// Parse flags before, between and after the positional arguments, which are
+// returned in order
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// Width of the longest bar of a chart
+const chartWidth = 40
+
+// Columns of listed and exported transactions, `import` reads the same ones
+var transactionColumns = []string{"id", "date", "type", "name", "amount", "category", "frequency", "description", "tags"}
+
+// Rows printed as a table or CSV
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+// `-o` flag of a command
+func formatFlag(fs *flag.FlagSet, value string) *string {
+	return fs.String("o", value, "Output format: table, json or csv")
+}
+
+func checkFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatCSV:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, use table, json or csv", format)
+}
+
+// Print `t` as a table or CSV, or `raw` as JSON
+func writeOutput(w io.Writer, format string, t *table, raw any) error {
+	switch format {
+	case formatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(raw)
+	case formatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(t.header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(t.rows); err != nil {
+			return err
+		}
+		return writer.Error()
+	}
+
+	writer := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.ToUpper(strings.Join(t.header, "\t")))
+	for _, row := range t.rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+func transactionTable(transactions []transactionModels.Transaction, categoryNames map[uint]string) *table {
+	t := &table{header: transactionColumns}
+	for _, transaction := range transactions {
+		tags := make([]string, 0, len(transaction.Tags))
+		for _, tag := range transaction.Tags {
+			tags = append(tags, tag.Name)
+		}
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(transaction.ID), 10),
+			transaction.TxnDate.Format("2006-01-02"),
+			transaction.TxnType,
+			transaction.Name,
+			formatAmount(transaction.Amount),
+			categoryNames[transaction.CategoryID],
+			transaction.Frequency,
+			transaction.Description,
+			strings.Join(tags, ";"),
+		})
+	}
+	return t
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// Horizontal bar per label, negative values are drawn with `-`
+func writeChart(w io.Writer, labels []string, values []float64) {
+	largest, labelWidth := 0.0, 0
+	for i, value := range values {
+		largest = math.Max(largest, math.Abs(value))
+		labelWidth = max(labelWidth, len(labels[i]))
+	}
+
+	for i, value := range values {
+		length := 0
+		if largest > 0 {
+			length = int(math.Round(math.Abs(value) / largest * chartWidth))
+		}
+		bar := strings.Repeat("#", length)
+		if value < 0 {
+			bar = strings.Repeat("-", length)
+		}
+		fmt.Fprintf(w, "%-*s  %-*s  %s\n", labelWidth, labels[i], chartWidth, bar, formatAmount(value))
+	}
+}
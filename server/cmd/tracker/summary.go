@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Dashboard metrics decoded for printing
+type summary struct {
+	metrics    *transactionModels.DashboardMetrics
+	netWorth   []summaryLine
+	months     []summaryLine
+	categories []summaryLine
+	tags       []summaryLine
+}
+
+type summaryLine struct {
+	label  string
+	amount float64
+}
+
+func newSummary(metrics *transactionModels.DashboardMetrics) (*summary, error) {
+	s := &summary{metrics: metrics}
+
+	if len(metrics.MonthlyTotals) > 0 {
+		monthly := map[string]float64{}
+		if err := json.Unmarshal(metrics.MonthlyTotals, &monthly); err != nil {
+			return nil, err
+		}
+		for month, amount := range monthly {
+			s.months = append(s.months, summaryLine{month, amount})
+		}
+		sort.Slice(s.months, func(i, j int) bool { return s.months[i].label < s.months[j].label })
+	}
+
+	if len(metrics.NetWorthByCurrency) > 0 {
+		byCurrency := map[string]float64{}
+		if err := json.Unmarshal(metrics.NetWorthByCurrency, &byCurrency); err != nil {
+			return nil, err
+		}
+		for currency, amount := range byCurrency {
+			s.netWorth = append(s.netWorth, summaryLine{currency, amount})
+		}
+		sort.Slice(s.netWorth, func(i, j int) bool { return s.netWorth[i].label < s.netWorth[j].label })
+	}
+
+	var err error
+	if s.categories, err = decodeAmounts(metrics.TopExpenseCategories, "category"); err != nil {
+		return nil, err
+	}
+	if s.tags, err = decodeAmounts(metrics.ExpenseByTag, "tag"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Decode a `[{<key>: label, amount: n}]` metric
+func decodeAmounts(data []byte, key string) ([]summaryLine, error) {
+	if len(data) == 0 || string(data) == "null" {
+		return nil, nil
+	}
+	var items []map[string]any
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	lines := make([]summaryLine, 0, len(items))
+	for _, item := range items {
+		label, _ := item[key].(string)
+		amount, _ := item["amount"].(float64)
+		lines = append(lines, summaryLine{label, amount})
+	}
+	return lines, nil
+}
+
+// One row per figure, grouped by section
+func (s *summary) table() *table {
+	t := &table{header: []string{"section", "label", "amount"}}
+	add := func(section, label string, amount float64) {
+		t.rows = append(t.rows, []string{section, label, formatAmount(amount)})
+	}
+	add("total", "income", s.metrics.TotalIncome)
+	add("total", "expense", s.metrics.TotalExpense)
+	add("total", "net savings", s.metrics.NetSavings)
+	if len(s.netWorth) == 0 {
+		add("total", "net worth", s.metrics.NetWorth)
+	}
+	for _, line := range s.netWorth {
+		add("net worth", line.label, line.amount)
+	}
+	for _, line := range s.months {
+		add("month", line.label, line.amount)
+	}
+	for _, line := range s.categories {
+		add("category", line.label, line.amount)
+	}
+	for _, line := range s.tags {
+		add("tag", line.label, line.amount)
+	}
+	return t
+}
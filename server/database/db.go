@@ -2,9 +2,13 @@ package database
 
 import (
 	"fmt"
+	"log/slog"
+	"time"
 
+	"github.com/niko-2609/tracker-expense/pkg/tracing"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
@@ -12,9 +16,34 @@ var DB *gorm.DB
 func ConnectDB() error {
 	var err error
 	dsn := "host=localhost user=postgres password=password dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Shanghai"
-	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
+		// Unique and foreign key violations become gorm.ErrDuplicatedKey and
+		// gorm.ErrForeignKeyViolated, see apierror.Handler
+		TranslateError: true,
+		// Slow queries and errors go to the JSON logs without their parameters
+		Logger: logger.NewSlogLogger(slog.Default(), logger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  logger.Warn,
+			IgnoreRecordNotFoundError: true,
+			ParameterizedQueries:      true,
+		}),
+	})
 	if err != nil {
 		return fmt.Errorf("Failed to connect to database")
 	}
-	return nil
+
+	// Span for every query, under the request's span when run `WithContext`
+	return DB.Use(tracing.GormPlugin{})
+}
+
+// Close the connection pool, once no requests are running
+func Close() error {
+	if DB == nil {
+		return nil
+	}
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
 }
@@ -0,0 +1,3 @@
+ALTER TABLE user_dashboard_metrics DROP COLUMN expense_by_tag;
+DROP TABLE transaction_tags;
+DROP TABLE tags;
@@ -0,0 +1,9 @@
+CREATE TABLE transaction_splits (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    category_id BIGINT NOT NULL REFERENCES categories(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    memo VARCHAR(255)
+);
+
+CREATE INDEX idx_transaction_splits_transaction_id ON transaction_splits(transaction_id);
@@ -0,0 +1,3 @@
+ALTER TABLE user_dashboard_metrics DROP COLUMN net_worth;
+ALTER TABLE transactions DROP COLUMN account_id;
+DROP TABLE accounts;
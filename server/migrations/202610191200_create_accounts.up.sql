@@ -0,0 +1,30 @@
+CREATE TABLE accounts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    account_type VARCHAR(20) NOT NULL CHECK (account_type IN ('checking','savings','credit_card','cash','loan')),
+    currency CHAR(3) NOT NULL,
+    opening_balance NUMERIC(12,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_accounts_user_id ON accounts(user_id);
+
+-- Existing transactions move to a default account per user
+INSERT INTO accounts (user_id, name, account_type, currency, opening_balance)
+SELECT DISTINCT user_id, 'Main', 'cash', 'USD', 0 FROM transactions;
+
+ALTER TABLE transactions ADD COLUMN account_id BIGINT REFERENCES accounts(id);
+
+UPDATE transactions t SET account_id = a.id
+FROM accounts a
+WHERE a.user_id = t.user_id;
+
+ALTER TABLE transactions ALTER COLUMN account_id SET NOT NULL;
+
+CREATE INDEX idx_transactions_account_id ON transactions(account_id);
+
+ALTER TABLE user_dashboard_metrics
+    ADD COLUMN net_worth NUMERIC(12,2) DEFAULT 0;
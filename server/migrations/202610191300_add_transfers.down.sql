@@ -0,0 +1,8 @@
+DELETE FROM transactions WHERE txn_type = 'transfer';
+
+ALTER TABLE transactions DROP COLUMN transfer_direction;
+ALTER TABLE transactions DROP COLUMN transfer_id;
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_txn_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_txn_type_check
+    CHECK (txn_type IN ('income','expense'));
@@ -0,0 +1,10 @@
+ALTER TABLE transactions DROP CONSTRAINT transactions_txn_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_txn_type_check
+    CHECK (txn_type IN ('income','expense','transfer'));
+
+-- Both legs of a transfer share the same transfer_id
+ALTER TABLE transactions ADD COLUMN transfer_id UUID;
+ALTER TABLE transactions ADD COLUMN transfer_direction VARCHAR(3)
+    CHECK (transfer_direction IN ('in','out'));
+
+CREATE INDEX idx_transactions_transfer_id ON transactions(transfer_id);
@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN goal_id;
+DROP TABLE savings_goals;
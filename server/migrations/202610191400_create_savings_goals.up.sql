@@ -0,0 +1,17 @@
+CREATE TABLE savings_goals (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    target_amount NUMERIC(12,2) NOT NULL CHECK (target_amount > 0),
+    deadline DATE,
+    account_id BIGINT REFERENCES accounts(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_savings_goals_user_id ON savings_goals(user_id);
+
+ALTER TABLE transactions ADD COLUMN goal_id BIGINT REFERENCES savings_goals(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_transactions_goal_id ON transactions(goal_id);
@@ -0,0 +1 @@
+DROP TABLE categorization_rules;
@@ -0,0 +1,21 @@
+CREATE TABLE categorization_rules (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    priority INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    match_field VARCHAR(20) NOT NULL CHECK (match_field IN ('name','description','any')),
+    match_type VARCHAR(20) NOT NULL CHECK (match_type IN ('contains','regex')),
+    pattern VARCHAR(255),
+    min_amount NUMERIC(12,2),
+    max_amount NUMERIC(12,2),
+    txn_type VARCHAR(10) CHECK (txn_type IN ('income','expense')),
+    set_category_id BIGINT REFERENCES categories(id),
+    set_tags JSONB DEFAULT '[]',
+    set_name VARCHAR(100),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_categorization_rules_user_id ON categorization_rules(user_id, priority);
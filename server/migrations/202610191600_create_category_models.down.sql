@@ -0,0 +1 @@
+DROP TABLE category_models;
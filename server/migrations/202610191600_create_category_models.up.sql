@@ -0,0 +1,6 @@
+CREATE TABLE category_models (
+    user_id BIGINT PRIMARY KEY,
+    model JSONB NOT NULL,           -- naive Bayes token and category counts
+    transaction_count INTEGER NOT NULL DEFAULT 0,
+    trained_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+DROP INDEX idx_transactions_user_date;
+DROP TABLE duplicate_dismissals;
@@ -0,0 +1,12 @@
+-- Pairs the user marked as not being duplicates, first_id < second_id
+CREATE TABLE duplicate_dismissals (
+    user_id BIGINT NOT NULL,
+    first_id BIGINT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    second_id BIGINT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (first_id, second_id),
+    CHECK (first_id < second_id)
+);
+
+CREATE INDEX idx_duplicate_dismissals_user_id ON duplicate_dismissals(user_id);
+CREATE INDEX idx_transactions_user_date ON transactions(user_id, txn_date);
@@ -0,0 +1,14 @@
+-- Stored responses of write requests sent with an Idempotency-Key header
+CREATE TABLE idempotency_keys (
+    user_id BIGINT NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    request_hash CHAR(64) NOT NULL,  -- sha256 of method, path and body
+    status_code INTEGER NOT NULL DEFAULT 0,  -- 0 while the request is in flight
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
@@ -0,0 +1,2 @@
+-- Optimistic concurrency, served as the ETag of a transaction
+ALTER TABLE transactions ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,3 @@
+DROP TRIGGER transaction_audits_append_only ON transaction_audits;
+DROP FUNCTION reject_audit_change();
+DROP TABLE transaction_audits;
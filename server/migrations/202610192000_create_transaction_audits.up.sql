@@ -0,0 +1,24 @@
+-- Append-only history of changes to transactions, no FK so records outlive the row
+CREATE TABLE transaction_audits (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,   -- owner of the transaction
+    actor_id BIGINT NOT NULL,  -- user who made the change
+    action VARCHAR(10) NOT NULL CHECK (action IN ('create','update','delete','restore')),
+    request_id VARCHAR(64),
+    ip VARCHAR(45),
+    changes JSONB NOT NULL,    -- field name to {before, after}
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_transaction_audits_transaction_id ON transaction_audits(transaction_id, id);
+
+CREATE FUNCTION reject_audit_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'transaction_audits is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transaction_audits_append_only
+    BEFORE UPDATE OR DELETE ON transaction_audits
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_change();
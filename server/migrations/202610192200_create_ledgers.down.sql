@@ -0,0 +1,5 @@
+DROP INDEX idx_transactions_ledger_id;
+ALTER TABLE transactions DROP COLUMN created_by, DROP COLUMN ledger_id;
+DROP TABLE ledger_invitations;
+DROP TABLE ledger_members;
+DROP TABLE ledgers;
@@ -0,0 +1,56 @@
+CREATE TABLE ledgers (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id BIGINT NOT NULL REFERENCES users(id),
+    personal BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP WITH TIME ZONE
+);
+
+-- One personal ledger per user
+CREATE UNIQUE INDEX idx_ledgers_personal ON ledgers(owner_id) WHERE personal AND deleted_at IS NULL;
+
+CREATE TABLE ledger_members (
+    ledger_id BIGINT NOT NULL REFERENCES ledgers(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    role VARCHAR(10) NOT NULL CHECK (role IN ('owner','editor','viewer')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (ledger_id, user_id)
+);
+
+CREATE INDEX idx_ledger_members_user_id ON ledger_members(user_id);
+
+CREATE TABLE ledger_invitations (
+    id BIGSERIAL PRIMARY KEY,
+    ledger_id BIGINT NOT NULL REFERENCES ledgers(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(10) NOT NULL CHECK (role IN ('editor','viewer')),
+    token UUID NOT NULL UNIQUE,
+    invited_by BIGINT NOT NULL REFERENCES users(id),
+    accepted_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Every user gets a personal ledger holding their existing transactions,
+-- deleted users too since their transactions are kept
+INSERT INTO ledgers (name, owner_id, personal)
+SELECT 'Personal', id, TRUE FROM users;
+
+INSERT INTO ledger_members (ledger_id, user_id, role)
+SELECT id, owner_id, 'owner' FROM ledgers;
+
+ALTER TABLE transactions
+    ADD COLUMN ledger_id BIGINT REFERENCES ledgers(id),
+    ADD COLUMN created_by BIGINT REFERENCES users(id);
+
+UPDATE transactions t SET ledger_id = l.id, created_by = t.user_id
+FROM ledgers l
+WHERE l.owner_id = t.user_id AND l.personal;
+
+ALTER TABLE transactions
+    ALTER COLUMN ledger_id SET NOT NULL,
+    ALTER COLUMN created_by SET NOT NULL;
+
+CREATE INDEX idx_transactions_ledger_id ON transactions(ledger_id);
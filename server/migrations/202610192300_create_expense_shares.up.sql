@@ -0,0 +1,22 @@
+CREATE TABLE expense_shares (
+    transaction_id BIGINT NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount >= 0),
+    PRIMARY KEY (transaction_id, user_id)
+);
+
+CREATE INDEX idx_expense_shares_user_id ON expense_shares(user_id);
+
+CREATE TABLE settlements (
+    id BIGSERIAL PRIMARY KEY,
+    ledger_id BIGINT NOT NULL REFERENCES ledgers(id) ON DELETE CASCADE,
+    from_user_id BIGINT NOT NULL REFERENCES users(id),
+    to_user_id BIGINT NOT NULL REFERENCES users(id),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    note VARCHAR(255),
+    created_by BIGINT NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    CHECK (from_user_id <> to_user_id)
+);
+
+CREATE INDEX idx_settlements_ledger_id ON settlements(ledger_id);
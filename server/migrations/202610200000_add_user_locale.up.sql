@@ -0,0 +1,2 @@
+-- Preferred language of validation messages, empty uses `Accept-Language`
+ALTER TABLE users ADD COLUMN locale VARCHAR(16) NOT NULL DEFAULT '';
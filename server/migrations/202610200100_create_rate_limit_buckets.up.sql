@@ -0,0 +1,10 @@
+-- Token buckets of the rate limiter when `RATE_LIMIT_STORE=postgres`
+CREATE TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,  -- route group and user id or client IP
+    tokens DOUBLE PRECISION NOT NULL,
+    allowed BOOLEAN NOT NULL,  -- whether the last request got a token
+    updated_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL  -- full again by then, safe to delete
+);
+
+CREATE INDEX idx_rate_limit_buckets_expires_at ON rate_limit_buckets(expires_at);
@@ -0,0 +1,3 @@
+DROP INDEX idx_accounts_auto_created;
+ALTER TABLE accounts DROP COLUMN auto_created;
+ALTER TABLE user_dashboard_metrics DROP COLUMN net_worth_by_currency;
@@ -0,0 +1,10 @@
+-- Net worth per currency, balances in different currencies are not added up
+ALTER TABLE user_dashboard_metrics
+    ADD COLUMN net_worth_by_currency JSONB DEFAULT '{}';  -- e.g., {"USD": 1200, "EUR": 300}
+
+-- The account created for users without one, at most one at a time so
+-- concurrent first requests agree on it
+ALTER TABLE accounts ADD COLUMN auto_created BOOLEAN NOT NULL DEFAULT false;
+
+CREATE UNIQUE INDEX idx_accounts_auto_created ON accounts(user_id)
+    WHERE auto_created AND deleted_at IS NULL;
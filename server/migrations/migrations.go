@@ -0,0 +1,30 @@
+// Package migrations embeds the SQL migrations, applied with golang-migrate
+// which records the current version in `schema_migrations`.
+package migrations
+
+import (
+	"embed"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var Files embed.FS
+
+// Version of the newest migration, the timestamp prefix of its file name
+func Latest() (uint64, error) {
+	entries, err := Files.ReadDir(".")
+	if err != nil {
+		return 0, err
+	}
+	var latest uint64
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
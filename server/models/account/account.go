@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Account types
+const (
+	AccountTypeChecking   = "checking"
+	AccountTypeSavings    = "savings"
+	AccountTypeCreditCard = "credit_card"
+	AccountTypeCash       = "cash"
+	AccountTypeLoan       = "loan"
+)
+
+// Account object, a wallet holding a user's transactions
+type Account struct {
+	gorm.Model
+	UserID         uint    `gorm:"not null" json:"user_id"`
+	Name           string  `gorm:"not null" json:"name"`
+	AccountType    string  `gorm:"type:enum('checking','savings','credit_card','cash','loan');not null" json:"account_type"`
+	Currency       string  `gorm:"not null" json:"currency"`
+	OpeningBalance float64 `gorm:"not null" json:"opening_balance"`
+	AutoCreated    bool    `gorm:"not null;default:false" json:"-"` // Made by GetDefaultAccount for a user without accounts
+}
+
+// Account with its current balance
+type AccountBalance struct {
+	ID             uint    `json:"id"`
+	Name           string  `json:"name"`
+	AccountType    string  `json:"account_type"`
+	Currency       string  `json:"currency"`
+	OpeningBalance float64 `json:"opening_balance"`
+	Balance        float64 `json:"balance"`
+}
+
+// Balance of an account at the end of a period
+type BalancePoint struct {
+	Date    time.Time `json:"date"`
+	Balance float64   `json:"balance"`
+}
+
+type AddAccountRequest struct {
+	Name           string  `json:"name" validate:"required,min=2,max=100"`
+	AccountType    string  `json:"account_type" validate:"required,oneof=checking savings credit_card cash loan"`
+	Currency       string  `json:"currency" validate:"required,len=3,uppercase"`
+	OpeningBalance float64 `json:"opening_balance"`
+}
+
+type UpdateAccountRequest struct {
+	Name           *string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	AccountType    *string  `json:"account_type,omitempty" validate:"omitempty,oneof=checking savings credit_card cash loan"`
+	Currency       *string  `json:"currency,omitempty" validate:"omitempty,len=3,uppercase"`
+	OpeningBalance *float64 `json:"opening_balance,omitempty"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Largest receipt that can be uploaded
+const MaxAttachmentSize = 10 << 20
+
+// Content types accepted as receipts, as detected from the file content
+var AllowedContentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+// Receipt attached to a transaction, the file itself lives in blob storage
+type Attachment struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	UserID        uint      `gorm:"not null" json:"user_id"`
+	TransactionID uint      `gorm:"not null" json:"transaction_id"`
+	FileName      string    `gorm:"not null" json:"file_name"`
+	ContentType   string    `gorm:"not null" json:"content_type"`
+	Size          int64     `gorm:"not null" json:"size"`
+	StorageKey    string    `gorm:"not null" json:"-"`
+	ThumbnailKey  *string   `json:"-"`
+	HasThumbnail  bool      `gorm:"-" json:"has_thumbnail"`
+	CreatedAt     time.Time `json:"created_at"`
+}
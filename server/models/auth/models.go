@@ -8,6 +8,7 @@ type User struct {
 	Username string `gorm:"uniqueIndex;not null" json:"username"`
 	Email    string `gorm:"uniqueIndex;not null" json:"email"`
 	Password string `gorm:"not null" json:"password"`
+	Locale   string `gorm:"not null;default:''" json:"locale"` // Language of validation messages
 }
 
 // Request for login
@@ -16,6 +17,11 @@ type Credentials struct {
 	Password string `json:"password" validate:"required,min=6,max=12"`
 }
 
+// Request to change the user's preferences
+type PreferencesRequest struct {
+	Locale string `json:"locale" validate:"required,oneof=en de es fr it ja nl pt pt_BR zh"`
+}
+
 // Success Response for login
 type AccessPayload struct {
 	Token string `json:"token" validate:"required"`
@@ -27,4 +33,5 @@ type UserCache struct {
 	Username string `json:"username"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Locale   string `json:"locale"`
 }
@@ -1,7 +1,15 @@
 package models
 
 type Response struct {
-	Status  string `json:"status"`
+	Status  string       `json:"status"`
+	Code    string       `json:"code,omitempty"` // Stable error code, see pkg/apierror
+	Message string       `json:"message"`
+	Data    any          `json:"data"`
+	Errors  []FieldError `json:"errors,omitempty"` // Invalid fields of a request
+}
+
+// Why a field of a request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
 	Message string `json:"message"`
-	Data    any    `json:"data"`
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Savings goal object, contributions are transactions linked through `goal_id`
+type SavingsGoal struct {
+	gorm.Model
+	UserID       uint       `gorm:"not null" json:"user_id"`
+	Name         string     `gorm:"not null" json:"name"`
+	TargetAmount float64    `gorm:"not null" json:"target_amount"`
+	Deadline     *time.Time `json:"deadline"`
+	AccountID    *uint      `json:"account_id"`
+}
+
+// Progress of a goal and how to reach it
+type GoalProgress struct {
+	Goal                SavingsGoal `json:"goal"`
+	SavedAmount         float64     `json:"saved_amount"`
+	RemainingAmount     float64     `json:"remaining_amount"`
+	PercentComplete     float64     `json:"percent_complete"`
+	MonthlyNeeded       *float64    `json:"monthly_contribution_needed"` // nil without a deadline
+	MonthlySavingsRate  float64     `json:"monthly_savings_rate"`        // Average net savings of recent months
+	ProjectedCompletion *time.Time  `json:"projected_completion_date"`   // nil when the user is not saving
+}
+
+type AddGoalRequest struct {
+	Name         string  `json:"name" validate:"required,min=2,max=100"`
+	TargetAmount float64 `json:"target_amount" validate:"required,gt=0"`
+	Deadline     string  `json:"deadline,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	AccountID    *uint   `json:"account_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+type UpdateGoalRequest struct {
+	Name         *string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	TargetAmount *float64 `json:"target_amount,omitempty" validate:"omitempty,gt=0"`
+	Deadline     *string  `json:"deadline,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	AccountID    *uint    `json:"account_id,omitempty" validate:"omitempty,gt=0"`
+}
+
+// Contribution to a goal. Either links an existing transaction, or moves `amount`
+// from `from_account_id` into the goal's account as a transfer.
+type ContributeRequest struct {
+	TransactionID *uint    `json:"transaction_id,omitempty" validate:"required_without=Amount,omitempty,gt=0"`
+	Amount        *float64 `json:"amount,omitempty" validate:"required_without=TransactionID,omitempty,gt=0"`
+	FromAccountID *uint    `json:"from_account_id,omitempty" validate:"required_with=Amount,omitempty,gt=0"`
+	Frequency     string   `json:"frequency,omitempty" validate:"required_with=Amount,omitempty,oneof=daily weekly monthly quarterly yearly"`
+	Description   string   `json:"description" validate:"max=255"`
+}
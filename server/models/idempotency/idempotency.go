@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Response stored for an Idempotency-Key, see pkg/middleware/idempotency
+type IdempotencyKey struct {
+	UserID       uint   `gorm:"primaryKey"`
+	Key          string `gorm:"primaryKey"`
+	RequestHash  string `gorm:"not null"`
+	StatusCode   int    `gorm:"not null"` // 0 while the request is in flight
+	ContentType  string `gorm:"not null"`
+	ResponseBody []byte
+	CreatedAt    time.Time
+	ExpiresAt    time.Time `gorm:"not null"`
+}
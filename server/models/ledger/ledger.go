@@ -0,0 +1,124 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Member roles, each one can do everything the ones below it can
+const (
+	RoleOwner  = "owner"  // Manages members and the ledger itself
+	RoleEditor = "editor" // Adds and changes transactions
+	RoleViewer = "viewer" // Reads transactions
+)
+
+// Ledger object, a book of transactions shared by its members.
+// Every user has a personal ledger that is used when none is picked.
+type Ledger struct {
+	gorm.Model
+	Name     string `gorm:"not null" json:"name"`
+	OwnerID  uint   `gorm:"not null" json:"owner_id"`
+	Personal bool   `gorm:"not null" json:"personal"`
+}
+
+// Membership of a user in a ledger
+type LedgerMember struct {
+	LedgerID  uint      `gorm:"primaryKey" json:"ledger_id"`
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	Role      string    `gorm:"type:enum('owner','editor','viewer');not null" json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Invitation to join a ledger, accepted by the user with the invited email
+type LedgerInvitation struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	LedgerID   uint       `gorm:"not null" json:"ledger_id"`
+	Email      string     `gorm:"not null" json:"email"`
+	Role       string     `gorm:"not null" json:"role"`
+	Token      string     `gorm:"type:uuid;not null" json:"token"`
+	InvitedBy  uint       `gorm:"not null" json:"invited_by"`
+	AcceptedAt *time.Time `json:"accepted_at,omitempty"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Ledger with the role of the requesting user
+type LedgerSummary struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	OwnerID  uint   `json:"owner_id"`
+	Personal bool   `json:"personal"`
+	Role     string `json:"role"`
+	Members  int    `json:"members"`
+}
+
+// Member with the user's public details
+type MemberSummary struct {
+	UserID   uint      `json:"user_id"`
+	Username string    `json:"username"`
+	Email    string    `json:"email"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+type AddLedgerRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+}
+
+type UpdateLedgerRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=2,max=100"`
+}
+
+// The owner role cannot be handed out, a ledger has exactly one owner
+type InviteMemberRequest struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+type UpdateMemberRequest struct {
+	Role string `json:"role" validate:"required,oneof=editor viewer"`
+}
+
+// Payment between two members that settles what one owes the other
+type Settlement struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	LedgerID   uint      `gorm:"not null" json:"ledger_id"`
+	FromUserID uint      `gorm:"not null" json:"from_user_id"`
+	ToUserID   uint      `gorm:"not null" json:"to_user_id"`
+	Amount     float64   `gorm:"not null" json:"amount"`
+	Note       string    `json:"note"`
+	CreatedBy  uint      `gorm:"not null" json:"created_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// What a member paid for shared expenses and owes for them. A positive
+// `Net` is owed to the member, a negative one is owed by the member.
+type MemberBalance struct {
+	UserID   uint    `json:"user_id"`
+	Username string  `json:"username"`
+	Paid     float64 `json:"paid"`
+	Owed     float64 `json:"owed"`
+	Net      float64 `json:"net"`
+}
+
+// Payment suggested to settle up
+type SettleUpPayment struct {
+	FromUserID uint    `json:"from_user_id"`
+	ToUserID   uint    `json:"to_user_id"`
+	Amount     float64 `json:"amount"`
+}
+
+// Balances of a ledger with the payments that settle them
+type LedgerBalances struct {
+	Balances []MemberBalance   `json:"balances"`
+	SettleUp []SettleUpPayment `json:"settle_up"`
+}
+
+// `FromUserID` defaults to the requesting user
+type AddSettlementRequest struct {
+	FromUserID uint    `json:"from_user_id,omitempty" validate:"omitempty,gt=0"`
+	ToUserID   uint    `json:"to_user_id" validate:"required,gt=0"`
+	Amount     float64 `json:"amount" validate:"required,gt=0"`
+	Note       string  `json:"note" validate:"max=255"`
+}
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Fields a rule can match on
+const (
+	MatchFieldName        = "name"
+	MatchFieldDescription = "description"
+	MatchFieldAny         = "any"
+)
+
+// How the pattern is matched
+const (
+	MatchTypeContains = "contains"
+	MatchTypeRegex    = "regex"
+)
+
+// Categorization rule object. Rules run in ascending `priority`, each action
+// (category, name) is taken from the first matching rule that sets it, tags add up.
+type CategorizationRule struct {
+	gorm.Model
+	UserID        uint                        `gorm:"not null" json:"user_id"`
+	Name          string                      `gorm:"not null" json:"name"`
+	Priority      int                         `gorm:"not null" json:"priority"`
+	Enabled       bool                        `gorm:"not null" json:"enabled"`
+	MatchField    string                      `gorm:"type:enum('name','description','any');not null" json:"match_field"`
+	MatchType     string                      `gorm:"type:enum('contains','regex');not null" json:"match_type"`
+	Pattern       string                      `json:"pattern"`
+	MinAmount     *float64                    `json:"min_amount"`
+	MaxAmount     *float64                    `json:"max_amount"`
+	TxnType       *string                     `json:"txn_type"`
+	SetCategoryID *uint                       `json:"set_category_id"`
+	SetTags       datatypes.JSONSlice[string] `json:"set_tags"`
+	SetName       *string                     `json:"set_name"`
+}
+
+type AddRuleRequest struct {
+	Name          string   `json:"name" validate:"required,min=2,max=100"`
+	Priority      int      `json:"priority" validate:"gte=0"`
+	Enabled       *bool    `json:"enabled,omitempty"`
+	MatchField    string   `json:"match_field" validate:"required,oneof=name description any"`
+	MatchType     string   `json:"match_type" validate:"required,oneof=contains regex"`
+	Pattern       string   `json:"pattern" validate:"max=255"`
+	MinAmount     *float64 `json:"min_amount,omitempty" validate:"omitempty,gte=0"`
+	MaxAmount     *float64 `json:"max_amount,omitempty" validate:"omitempty,gte=0"`
+	TxnType       *string  `json:"txn_type,omitempty" validate:"omitempty,oneof=income expense"`
+	SetCategoryID *uint    `json:"set_category_id,omitempty" validate:"omitempty,gt=0"`
+	SetTags       []string `json:"set_tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
+	SetName       *string  `json:"set_name,omitempty" validate:"omitempty,min=2,max=100"`
+}
+
+type UpdateRuleRequest struct {
+	Name          *string   `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Priority      *int      `json:"priority,omitempty" validate:"omitempty,gte=0"`
+	Enabled       *bool     `json:"enabled,omitempty"`
+	MatchField    *string   `json:"match_field,omitempty" validate:"omitempty,oneof=name description any"`
+	MatchType     *string   `json:"match_type,omitempty" validate:"omitempty,oneof=contains regex"`
+	Pattern       *string   `json:"pattern,omitempty" validate:"omitempty,max=255"`
+	MinAmount     *float64  `json:"min_amount,omitempty" validate:"omitempty,gte=0"`
+	MaxAmount     *float64  `json:"max_amount,omitempty" validate:"omitempty,gte=0"`
+	TxnType       *string   `json:"txn_type,omitempty" validate:"omitempty,oneof=income expense"`
+	SetCategoryID *uint     `json:"set_category_id,omitempty" validate:"omitempty,gt=0"`
+	SetTags       *[]string `json:"set_tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
+	SetName       *string   `json:"set_name,omitempty" validate:"omitempty,min=2,max=100"`
+}
+
+// Request to re-run the rules on existing transactions
+type ApplyRulesRequest struct {
+	DryRun            bool   `json:"dry_run"`
+	TransactionIDs    []uint `json:"transaction_ids,omitempty" validate:"omitempty,max=1000,dive,gt=0"`
+	OnlyUncategorized bool   `json:"only_uncategorized"`
+}
+
+// Snapshot of the fields rules can change
+type RuleTarget struct {
+	Name       string   `json:"name"`
+	CategoryID uint     `json:"category_id"`
+	Tags       []string `json:"tags"`
+}
+
+// Change the rules make, or would make in a dry run, to a transaction
+type RuleChange struct {
+	TransactionID uint       `json:"transaction_id"`
+	RuleIDs       []uint     `json:"rule_ids"`
+	Before        RuleTarget `json:"before"`
+	After         RuleTarget `json:"after"`
+}
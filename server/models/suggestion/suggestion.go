@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// Trained category model of a user, see pkg/suggest
+type CategoryModel struct {
+	UserID           uint           `gorm:"primaryKey" json:"user_id"`
+	Model            datatypes.JSON `gorm:"not null" json:"-"`
+	TransactionCount int            `gorm:"not null" json:"transaction_count"`
+	TrainedAt        time.Time      `gorm:"not null" json:"trained_at"`
+}
+
+type SuggestCategoryRequest struct {
+	Name        string  `json:"name" validate:"required,min=2,max=100"`
+	Amount      float64 `json:"amount" validate:"omitempty,gt=0"`
+	Description string  `json:"description" validate:"max=255"`
+}
+
+// Ranked category suggestion, `score` is the probability given the model
+type CategorySuggestion struct {
+	CategoryID   uint    `json:"category_id"`
+	CategoryName string  `json:"category_name"`
+	Score        float64 `json:"score"`
+}
@@ -7,35 +7,128 @@ import (
 	"gorm.io/gorm"
 )
 
+// Transaction types
+const (
+	TxnTypeIncome   = "income"
+	TxnTypeExpense  = "expense"
+	TxnTypeTransfer = "transfer"
+)
+
+// Direction of a transfer leg
+const (
+	TransferOut = "out"
+	TransferIn  = "in"
+)
+
 // Transaction object
 type Transaction struct {
 	gorm.Model
-	UserID      uint      `gorm:"not null" json:"user_id"`
-	Name        string    `gorm:"not null" json:"name"`
-	Amount      float64   `gorm:"not null" json:"amount"`
-	TxnType     string    `gorm:"type:enum('income','expense');not null" json:"txn_type"`
-	Frequency   string    `gorm:"type:enum('daily','weekly','monthly','quarterly','yearly');not null" json:"frequency"`
-	CategoryID  uint      `json:"category_id"`
-	TxnDate     time.Time `gorm:"not null" json:"txn_date"`
-	Description string    `json:"description"`
+	UserID            uint               `gorm:"not null" json:"user_id"` // Holder of the account
+	LedgerID          uint               `gorm:"not null" json:"ledger_id"`
+	CreatedBy         uint               `gorm:"not null" json:"created_by"` // Member who entered it
+	AccountID         uint               `gorm:"not null" json:"account_id"`
+	Name              string             `gorm:"not null" json:"name"`
+	Amount            float64            `gorm:"not null" json:"amount"`
+	TxnType           string             `gorm:"type:enum('income','expense','transfer');not null" json:"txn_type"`
+	Frequency         string             `gorm:"type:enum('daily','weekly','monthly','quarterly','yearly');not null" json:"frequency"`
+	CategoryID        uint               `gorm:"default:null" json:"category_id"`
+	TxnDate           time.Time          `gorm:"not null" json:"txn_date"`
+	Description       string             `json:"description"`
+	TransferID        *string            `gorm:"type:uuid" json:"transfer_id,omitempty"`           // Shared by both legs of a transfer
+	TransferDirection string             `gorm:"default:null" json:"transfer_direction,omitempty"` // `in` or `out` for transfer legs
+	GoalID            *uint              `json:"goal_id,omitempty"`                                // Savings goal this transaction contributes to
+	Tags              []Tag              `gorm:"many2many:transaction_tags;" json:"tags,omitempty"`
+	Splits            []TransactionSplit `json:"splits,omitempty"`
+	Shares            []ExpenseShare     `json:"shares,omitempty"`                  // Members' shares of an expense in a shared ledger
+	Version           uint               `gorm:"not null;default:1" json:"version"` // Bumped on every change, served as the ETag
+}
+
+// Split line of a transaction, all lines add up to the parent amount
+type TransactionSplit struct {
+	ID            uint    `gorm:"primarykey" json:"id"`
+	TransactionID uint    `gorm:"not null" json:"transaction_id"`
+	CategoryID    uint    `gorm:"not null" json:"category_id"`
+	Amount        float64 `gorm:"not null" json:"amount"`
+	Memo          string  `json:"memo"`
+}
+
+type SplitRequest struct {
+	CategoryID uint    `json:"category_id" validate:"required,gt=0"`
+	Amount     float64 `json:"amount" validate:"required,gt=0"`
+	Memo       string  `json:"memo" validate:"max=255"`
+}
+
+// How an expense is divided between ledger members
+const (
+	ShareEqual      = "equal"      // Same amount for every member
+	ShareExact      = "exact"      // `Value` is the member's amount
+	SharePercentage = "percentage" // `Value` is the member's percentage
+	ShareShares     = "shares"     // `Value` is the member's number of shares
+)
+
+// Part of an expense owed by a ledger member, all shares add up to the
+// parent amount. The transaction's user paid for it.
+type ExpenseShare struct {
+	TransactionID uint    `gorm:"primaryKey" json:"transaction_id"`
+	UserID        uint    `gorm:"primaryKey" json:"user_id"`
+	Amount        float64 `gorm:"not null" json:"amount"`
+}
+
+type ShareMemberRequest struct {
+	UserID uint    `json:"user_id" validate:"required,gt=0"`
+	Value  float64 `json:"value" validate:"gte=0"` // Ignored for `equal`
+}
+
+// Request to split an expense between ledger members, replaces earlier shares
+type ShareExpenseRequest struct {
+	Method  string               `json:"method" validate:"required,oneof=equal exact percentage shares"`
+	Members []ShareMemberRequest `json:"members" validate:"required,min=1,max=50,dive"`
 }
 
 type AddTransactionRequest struct {
-	Name        string  `json:"name" validate:"required,min=2,max=100"`
-	Amount      float64 `json:"amount" validate:"required,gt=0"`
-	TxnType     string  `json:"txn_type" validate:"required,oneof=income expense"`
-	Frequency   string  `json:"frequency" validate:"required,oneof=daily weekly monthly quarterly yearly"`
-	CategoryID  uint    `json:"category_id" validate:"required,gt=0"`
-	Description string  `json:"description" validate:"max=255"`
+	Name        string         `json:"name" validate:"required,min=2,max=100"`
+	Amount      float64        `json:"amount" validate:"required,gt=0"`
+	TxnType     string         `json:"txn_type" validate:"required,oneof=income expense"`
+	Frequency   string         `json:"frequency" validate:"required,oneof=daily weekly monthly quarterly yearly"`
+	CategoryID  uint           `json:"category_id" validate:"omitempty,gt=0"`          // Set by categorization rules when empty
+	AccountID   uint           `json:"account_id,omitempty" validate:"omitempty,gt=0"` // Defaults to the user's first account
+	LedgerID    uint           `json:"ledger_id,omitempty" validate:"omitempty,gt=0"`  // Defaults to the user's personal ledger
+	Description string         `json:"description" validate:"max=255"`
+	TxnDate     string         `json:"txn_date,omitempty" validate:"omitempty,datetime=2006-01-02"` // Defaults to today
+	Tags        []string       `json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Splits      []SplitRequest `json:"splits,omitempty" validate:"omitempty,max=50,dive"` // Must add up to `Amount`
 }
 
 type UpdateTransactionRequest struct {
-	Name        *string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
-	Amount      *float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
-	TxnType     *string  `json:"txn_type,omitempty" validate:"omitempty,oneof=income expense"`
-	Frequency   *string  `json:"frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly quarterly yearly"`
-	CategoryID  *uint    `json:"category_id,omitempty" validate:"omitempty,gt=0"`
-	Description *string  `json:"description,omitempty" validate:"omitempty,max=255"`
+	Name        *string         `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Amount      *float64        `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	TxnType     *string         `json:"txn_type,omitempty" validate:"omitempty,oneof=income expense"`
+	Frequency   *string         `json:"frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly quarterly yearly"`
+	CategoryID  *uint           `json:"category_id,omitempty" validate:"omitempty,gt=0"`
+	AccountID   *uint           `json:"account_id,omitempty" validate:"omitempty,gt=0"`
+	Description *string         `json:"description,omitempty" validate:"omitempty,max=255"`
+	Tags        *[]string       `json:"tags,omitempty" validate:"omitempty,max=20,dive,min=1,max=50"`
+	Splits      *[]SplitRequest `json:"splits,omitempty" validate:"omitempty,max=50,dive"` // Replaces all lines, empty list removes them
+}
+
+// Request for a transfer between two accounts of the user
+type AddTransferRequest struct {
+	FromAccountID uint    `json:"from_account_id" validate:"required,gt=0"`
+	ToAccountID   uint    `json:"to_account_id" validate:"required,gt=0,nefield=FromAccountID"`
+	Amount        float64 `json:"amount" validate:"required,gt=0"`
+	Name          string  `json:"name" validate:"omitempty,min=2,max=100"`
+	Frequency     string  `json:"frequency" validate:"required,oneof=daily weekly monthly quarterly yearly"`
+	Description   string  `json:"description" validate:"max=255"`
+	LedgerID      uint    `json:"ledger_id,omitempty" validate:"omitempty,gt=0"` // Defaults to the user's personal ledger
+}
+
+type UpdateTransferRequest struct {
+	FromAccountID *uint    `json:"from_account_id,omitempty" validate:"omitempty,gt=0"`
+	ToAccountID   *uint    `json:"to_account_id,omitempty" validate:"omitempty,gt=0"`
+	Amount        *float64 `json:"amount,omitempty" validate:"omitempty,gt=0"`
+	Name          *string  `json:"name,omitempty" validate:"omitempty,min=2,max=100"`
+	Frequency     *string  `json:"frequency,omitempty" validate:"omitempty,oneof=daily weekly monthly quarterly yearly"`
+	Description   *string  `json:"description,omitempty" validate:"omitempty,max=255"`
 }
 
 // Category object
@@ -45,6 +138,90 @@ type Category struct {
 	Type string `gorm:"type:enum('income','expense');not null" json:"type"`
 }
 
+// Category as listed to clients
+type CategorySummary struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Tag object, user defined labels attached to transactions
+type Tag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	Name      string    `gorm:"not null" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Tag with the number of transactions it is attached to
+type TagSummary struct {
+	ID               uint   `json:"id"`
+	Name             string `json:"name"`
+	TransactionCount int64  `json:"transaction_count"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=50"`
+}
+
+type MergeTagsRequest struct {
+	SourceIDs []uint `json:"source_ids" validate:"required,min=1,dive,gt=0"`
+	TargetID  uint   `json:"target_id" validate:"required,gt=0"`
+}
+
+// Pair of transactions the user marked as not being duplicates
+type DuplicateDismissal struct {
+	UserID    uint      `gorm:"not null" json:"user_id"`
+	FirstID   uint      `gorm:"primaryKey" json:"first_id"`
+	SecondID  uint      `gorm:"primaryKey" json:"second_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Two transactions that look like the same entry
+type DuplicatePair struct {
+	First      Transaction `json:"first"`
+	Second     Transaction `json:"second"`
+	Similarity float64     `json:"similarity"`
+}
+
+type DismissDuplicateRequest struct {
+	TransactionIDs []uint `json:"transaction_ids" validate:"required,len=2,dive,gt=0"`
+}
+
+// Keeps `keep_id` and removes `remove_id`, tags of both are kept
+type MergeDuplicateRequest struct {
+	KeepID   uint `json:"keep_id" validate:"required,gt=0"`
+	RemoveID uint `json:"remove_id" validate:"required,gt=0,nefield=KeepID"`
+}
+
+// Audit actions on a transaction
+const (
+	AuditCreate  = "create"
+	AuditUpdate  = "update"
+	AuditDelete  = "delete"
+	AuditRestore = "restore"
+)
+
+// Append-only record of a change to a transaction
+type TransactionAudit struct {
+	ID            uint           `gorm:"primarykey" json:"id"`
+	TransactionID uint           `gorm:"not null" json:"transaction_id"`
+	UserID        uint           `gorm:"not null" json:"user_id"`  // Owner of the transaction
+	ActorID       uint           `gorm:"not null" json:"actor_id"` // User who made the change
+	Action        string         `gorm:"not null" json:"action"`
+	RequestID     string         `json:"request_id"`
+	IP            string         `gorm:"column:ip" json:"ip"`
+	Changes       datatypes.JSON `gorm:"not null" json:"changes"` // Field name to FieldChange
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// Value of a field before and after a change
+type FieldChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
 // Metrics object
 
 type DashboardMetrics struct {
@@ -54,5 +231,54 @@ type DashboardMetrics struct {
 	NetSavings           float64        `json:"net_savings"`
 	MonthlyTotals        datatypes.JSON `json:"monthly_totals"`         // JSONB for monthly line chart
 	TopExpenseCategories datatypes.JSON `json:"top_expense_categories"` // JSONB for pie chart
+	ExpenseByTag         datatypes.JSON `json:"expense_by_tag"`         // JSONB for spending by tag
+	NetWorth             float64        `json:"net_worth"`              // In the currency of the default account
+	NetWorthByCurrency   datatypes.JSON `json:"net_worth_by_currency"`  // JSONB, net worth per account currency
 	UpdatedAt            time.Time      `json:"updated_at"`
 }
+
+// Bulk request modes
+const (
+	BulkModeAtomic     = "atomic"
+	BulkModeBestEffort = "best_effort"
+)
+
+// Bulk operation types
+const (
+	BulkOpCreate = "create"
+	BulkOpUpdate = "update"
+	BulkOpDelete = "delete"
+)
+
+// Request for bulk create/update/delete of transactions
+type BulkTransactionRequest struct {
+	Mode       string          `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Force      bool            `json:"force"` // Create items even when they look like duplicates
+	Operations []BulkOperation `json:"operations" validate:"required,min=1,max=100,dive"`
+}
+
+// Single item of a bulk request. `Create` is used for `create`,
+// `ID` with `Version` for `delete` and `ID`, `Version` and `Update` for `update`.
+type BulkOperation struct {
+	Op      string                    `json:"op" validate:"required,oneof=create update delete"`
+	ID      *uint                     `json:"id,omitempty" validate:"required_unless=Op create,omitempty,gt=0"`
+	Create  *AddTransactionRequest    `json:"create,omitempty" validate:"required_if=Op create"`
+	Update  *UpdateTransactionRequest `json:"update,omitempty" validate:"required_if=Op update"`
+	Version uint                      `json:"version,omitempty"` // Required for update and delete, the version the item must be at, like `If-Match`
+}
+
+// Result of a single bulk operation
+type BulkOperationResult struct {
+	Index      int    `json:"index"`
+	Op         string `json:"op"`
+	ID         uint   `json:"id,omitempty"`
+	Status     string `json:"status"`
+	Code       string `json:"code,omitempty"` // Error code of a failed item, see pkg/apierror
+	Message    string `json:"message,omitempty"`
+	Duplicates []uint `json:"duplicates,omitempty"` // Suspected duplicates of a created item
+}
+
+// Metrics live in `user_dashboard_metrics`, see UpdateDashboardMetrics
+func (DashboardMetrics) TableName() string {
+	return "user_dashboard_metrics"
+}
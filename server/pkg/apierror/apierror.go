@@ -0,0 +1,175 @@
+// Package apierror is the error type handlers return instead of writing
+// error responses themselves. `Handler` turns it, and any other error, into
+// the `{status, code, message, data, errors}` response. Details of internal
+// errors only go to the logs.
+package apierror
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+)
+
+// Stable error codes, clients match on these instead of the message
+const (
+	CodeValidationFailed     = "VALIDATION_FAILED"
+	CodeBadRequest           = "BAD_REQUEST"
+	CodeUnauthorized         = "UNAUTHORIZED"
+	CodeForbidden            = "FORBIDDEN"
+	CodeNotFound             = "NOT_FOUND"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeConflict             = "CONFLICT"
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodePayloadTooLarge      = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnprocessable        = "UNPROCESSABLE"
+	CodeTooManyRequests      = "TOO_MANY_REQUESTS"
+	CodeInternal             = "INTERNAL"
+	CodeUnavailable          = "UNAVAILABLE"
+)
+
+// Every code, in the order of their statuses
+var Codes = []string{
+	CodeValidationFailed, CodeBadRequest, CodeUnauthorized, CodeForbidden, CodeNotFound,
+	CodeMethodNotAllowed, CodeConflict, CodePreconditionFailed, CodePayloadTooLarge,
+	CodeUnsupportedMediaType, CodeUnprocessable, CodePreconditionRequired, CodeTooManyRequests,
+	CodeInternal, CodeUnavailable,
+}
+
+// Error response of a request
+type Error struct {
+	Status  int
+	Code    string
+	Message string                // Shown to the client
+	Fields  []apiModel.FieldError // Invalid fields of the request
+	Data    any                   // E.g. the candidates of a duplicate
+	Err     error                 // Internal cause, only logged
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Send `data` along with the error
+func (e *Error) WithData(data any) *Error {
+	e.Data = data
+	return e
+}
+
+// Error with the code of its status
+func New(status int, message string) *Error {
+	return &Error{Status: status, Code: CodeFor(status), Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(fiber.StatusBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(fiber.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(fiber.StatusForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(fiber.StatusNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(fiber.StatusConflict, message)
+}
+
+func PreconditionFailed(message string) *Error {
+	return New(fiber.StatusPreconditionFailed, message)
+}
+
+// Server error, `err` is logged and `message` is sent to the client
+func Internal(err error, message string) *Error {
+	e := New(fiber.StatusInternalServerError, message)
+	e.Err = err
+	return e
+}
+
+// A single invalid field, e.g. a referenced account that does not exist
+func Invalid(field, message string) *Error {
+	return &Error{
+		Status:  fiber.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: fmt.Sprintf("Invalid request - %s: %s", field, message),
+		Fields:  []apiModel.FieldError{{Field: field, Message: message}},
+	}
+}
+
+// Error for the result of `validation.ValidateRequest`. Failed rules become
+// field errors, their translated messages already name the field. A body
+// that cannot be decoded is a bad request, the decoder's error describes
+// our types and is only logged.
+func Validation(errs []validation.ValidationError, err error) *Error {
+	if len(errs) == 0 {
+		e := BadRequest("Request body is not valid JSON")
+		e.Err = err
+		return e
+	}
+
+	fields := make([]apiModel.FieldError, 0, len(errs))
+	messages := make([]string, 0, len(errs))
+	for _, fieldErr := range errs {
+		fields = append(fields, apiModel.FieldError{Field: fieldErr.Field, Message: fieldErr.Message})
+		messages = append(messages, fieldErr.Message)
+	}
+	return &Error{
+		Status:  fiber.StatusBadRequest,
+		Code:    CodeValidationFailed,
+		Message: "Invalid request - " + strings.Join(messages, "; "),
+		Fields:  fields,
+	}
+}
+
+// Code of errors with the status
+func CodeFor(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return CodeBadRequest
+	case fiber.StatusUnauthorized:
+		return CodeUnauthorized
+	case fiber.StatusForbidden:
+		return CodeForbidden
+	case fiber.StatusNotFound:
+		return CodeNotFound
+	case fiber.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case fiber.StatusConflict:
+		return CodeConflict
+	case fiber.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case fiber.StatusRequestEntityTooLarge:
+		return CodePayloadTooLarge
+	case fiber.StatusUnsupportedMediaType:
+		return CodeUnsupportedMediaType
+	case fiber.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case fiber.StatusPreconditionRequired:
+		return CodePreconditionRequired
+	case fiber.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case fiber.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= fiber.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
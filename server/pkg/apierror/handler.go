@@ -0,0 +1,87 @@
+package apierror
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/utils"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// Domain errors that reach the handler without being mapped by the route
+var domainErrors = []struct {
+	err   error
+	build func() *Error
+}{
+	{gorm.ErrRecordNotFound, func() *Error { return NotFound("Not found") }},
+	{gorm.ErrDuplicatedKey, func() *Error { return Conflict("Already exists") }},
+	{gorm.ErrForeignKeyViolated, func() *Error { return Conflict("Still in use or refers to a missing record") }},
+	{utils.ErrLedgerNotFound, func() *Error { return NotFound("Ledger not found") }},
+	{utils.ErrLedgerForbidden, func() *Error { return Forbidden("Your role in this ledger does not allow this") }},
+	{utils.ErrAccountNotFound, func() *Error { return Invalid("account_id", "account not found") }},
+	{utils.ErrSameAccount, func() *Error { return Invalid("to_account_id", "must differ from from_account_id") }},
+	{utils.ErrCurrencyMismatch, func() *Error { return Invalid("to_account_id", "must use the currency of from_account_id") }},
+	{context.DeadlineExceeded, func() *Error { return New(fiber.StatusServiceUnavailable, "Request timed out, please try again") }},
+}
+
+// Fiber `ErrorHandler` for all routes. Writes the error response and logs
+// server errors and the internal cause of client errors with the request.
+func Handler(c *fiber.Ctx, err error) error {
+	apiErr := From(err)
+
+	if apiErr.Status >= fiber.StatusInternalServerError {
+		logs.Ctx(c).Error(apiErr.Message, "code", apiErr.Code, "error", err)
+	} else if apiErr.Err != nil {
+		logs.Ctx(c).Warn(apiErr.Message, "code", apiErr.Code, "error", err)
+	}
+
+	return c.Status(apiErr.Status).JSON(apiModel.Response{
+		Status:  "error",
+		Code:    apiErr.Code,
+		Message: apiErr.Message,
+		Data:    apiErr.Data,
+		Errors:  apiErr.Fields,
+	})
+}
+
+// Writes the error response of the routes after it and returns nil, so the
+// middleware before it, such as logging, tracing and metrics, reads the final
+// status from `c.Response()`. The error is recorded on the request's span.
+func Middleware(c *fiber.Ctx) error {
+	err := c.Next()
+	if err == nil {
+		return nil
+	}
+	trace.SpanFromContext(c.UserContext()).RecordError(err)
+	if handlerErr := c.App().ErrorHandler(c, err); handlerErr != nil {
+		_ = c.SendStatus(fiber.StatusInternalServerError)
+	}
+	return nil
+}
+
+// Error response for any error, unknown errors are internal
+func From(err error) *Error {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	// Routing errors such as 404 and 405, their messages are safe to show
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return New(fiberErr.Code, fiberErr.Message)
+	}
+
+	for _, domain := range domainErrors {
+		if errors.Is(err, domain.err) {
+			mapped := domain.build()
+			mapped.Err = err
+			return mapped
+		}
+	}
+	return Internal(err, "Internal server error, please try again")
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	authModels "github.com/niko-2609/tracker-expense/models/auth"
+)
+
+// Tokens are refreshed this long before they expire
+const refreshMargin = time.Minute
+
+// Auth endpoints. The API has no refresh endpoint, after `Login` the client
+// keeps the credentials and logs in again before the token expires or once
+// a request is rejected with 401.
+type AuthService struct {
+	client *Client
+}
+
+// Log in and use the token for all further requests
+func (s *AuthService) Login(ctx context.Context, email, password string) error {
+	if _, err := s.login(ctx, email, password); err != nil {
+		return err
+	}
+	s.client.mu.Lock()
+	s.client.email, s.client.password = email, password
+	s.client.mu.Unlock()
+	return nil
+}
+
+// Sign up, log in afterwards to use the account
+func (s *AuthService) Register(ctx context.Context, email, password string) error {
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/register",
+		body:   authModels.Credentials{Email: email, Password: password},
+		public: true,
+	}, nil)
+	return err
+}
+
+// Current token, empty before logging in
+func (s *AuthService) Token() string {
+	s.client.mu.Lock()
+	defer s.client.mu.Unlock()
+	return s.client.token
+}
+
+// Use the token for all further requests
+func (s *AuthService) SetToken(token string) {
+	s.client.setToken(token)
+}
+
+// Save the locale of validation messages and use the token carrying it
+func (s *AuthService) SetLocale(ctx context.Context, locale string) error {
+	payload := new(authModels.AccessPayload)
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPatch,
+		path:   "/api/auth/preferences",
+		body:   authModels.PreferencesRequest{Locale: locale},
+	}, payload)
+	if err != nil {
+		return err
+	}
+	s.client.setToken(payload.Token)
+	return nil
+}
+
+func (s *AuthService) login(ctx context.Context, email, password string) (string, error) {
+	payload := new(authModels.AccessPayload)
+	_, err := s.client.do(ctx, request{
+		method: http.MethodPost,
+		path:   "/api/auth/login",
+		body:   authModels.Credentials{Email: email, Password: password},
+		public: true,
+	}, payload)
+	if err != nil {
+		return "", err
+	}
+	s.client.setToken(payload.Token)
+	return payload.Token, nil
+}
+
+func (c *Client) setToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+	c.tokenExpiry = tokenExpiry(token)
+}
+
+func (c *Client) canRefresh() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.email != ""
+}
+
+// Token for the next request, logs in again when it is about to expire
+func (c *Client) validToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	token, expiry, canRefresh := c.token, c.tokenExpiry, c.email != ""
+	c.mu.Unlock()
+
+	if canRefresh && (token == "" || (!expiry.IsZero() && time.Until(expiry) < refreshMargin)) {
+		return c.refreshToken(ctx)
+	}
+	return token, nil
+}
+
+func (c *Client) refresh(ctx context.Context) error {
+	_, err := c.refreshToken(ctx)
+	return err
+}
+
+func (c *Client) refreshToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	email, password := c.email, c.password
+	c.mu.Unlock()
+	if email == "" {
+		return "", errNoCredentials
+	}
+	return c.Auth.login(ctx, email, password)
+}
+
+// Expiry from the `exp` claim, zero when the token cannot be read. The
+// signature is not verified, the server does that.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
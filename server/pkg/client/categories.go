@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	suggestionModels "github.com/niko-2609/tracker-expense/models/suggestion"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Category endpoints
+type CategoriesService struct {
+	client *Client
+}
+
+// All categories
+func (s *CategoriesService) List(ctx context.Context) ([]transactionModels.CategorySummary, error) {
+	var categories []transactionModels.CategorySummary
+	_, err := s.client.get(ctx, "/api/categories", nil, &categories)
+	return categories, err
+}
+
+// Categories for a new transaction, most likely first
+func (s *CategoriesService) Suggest(ctx context.Context, req *suggestionModels.SuggestCategoryRequest) ([]suggestionModels.CategorySuggestion, error) {
+	var suggestions []suggestionModels.CategorySuggestion
+	_, err := s.client.send(ctx, http.MethodPost, "/api/transaction/suggest-category", req, nil, &suggestions)
+	return suggestions, err
+}
@@ -0,0 +1,277 @@
+// Package client is a typed Go client for the tracker-expense API.
+//
+//	c := client.New("http://localhost:3000")
+//	if err := c.Auth.Login(ctx, email, password); err != nil { ... }
+//	transactions, err := c.Transactions.List(ctx, nil)
+//
+// Requests honour the context, 5xx responses and network errors are retried
+// with exponential backoff, 429 responses after their `Retry-After`. POST and PATCH requests carry an Idempotency-Key
+// so a retry never applies a change twice.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+)
+
+const (
+	defaultRetries = 3
+	defaultBackoff = 200 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+)
+
+// Client for the API, safe for concurrent use
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	email       string
+	password    string
+
+	Auth         *AuthService
+	Transactions *TransactionsService
+	Categories   *CategoriesService
+	Dashboard    *DashboardService
+}
+
+type Option func(*Client)
+
+// Use a custom HTTP client, e.g. for timeouts or a proxy
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Retry 5xx responses and network errors up to `retries` times, waiting
+// `backoff` before the first retry and doubling it for every further one
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// Authenticate with an existing token instead of logging in
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.setToken(token)
+	}
+}
+
+// New client for the API at `baseURL`, e.g. `http://localhost:3000`
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.Auth = &AuthService{client: c}
+	c.Transactions = &TransactionsService{client: c}
+	c.Categories = &CategoriesService{client: c}
+	c.Dashboard = &DashboardService{client: c}
+	return c
+}
+
+// Single API call
+type request struct {
+	method  string
+	path    string
+	query   url.Values
+	body    any
+	header  http.Header
+	public  bool // Sent without a token
+	retried bool // Already retried after refreshing the token
+}
+
+// Send the request and decode `data` of the response into `out` when set.
+// Returns the response headers.
+func (c *Client) do(ctx context.Context, req request, out any) (http.Header, error) {
+	var payload []byte
+	if req.body != nil {
+		var err error
+		if payload, err = json.Marshal(req.body); err != nil {
+			return nil, err
+		}
+	}
+
+	header := req.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	if req.body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+	header.Set("Accept", "application/json")
+	// Same key for every attempt so the server applies the change only once
+	if req.method == http.MethodPost || req.method == http.MethodPatch {
+		if header.Get("Idempotency-Key") == "" && !req.public {
+			header.Set("Idempotency-Key", uuid.NewString())
+		}
+	}
+
+	target := c.baseURL + req.path
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	var resp *http.Response
+	var body []byte
+	for attempt := 0; ; attempt++ {
+		if !req.public {
+			token, err := c.validToken(ctx)
+			if err != nil {
+				return nil, err
+			}
+			if token != "" {
+				header.Set("Authorization", "Bearer "+token)
+			}
+		}
+
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, target, bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header = header.Clone()
+
+		resp, err = c.httpClient.Do(httpReq)
+		if err == nil {
+			body, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		// Rate limited, retried when the wait is short enough
+		var retryAfter time.Duration
+		if err == nil && resp.StatusCode == http.StatusTooManyRequests {
+			retryAfter = parseRetryAfter(resp.Header)
+		}
+
+		retry := err != nil || resp.StatusCode >= http.StatusInternalServerError ||
+			(retryAfter > 0 && retryAfter <= maxBackoff)
+		if !retry || attempt >= c.retries || ctx.Err() != nil {
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		if retryAfter > 0 {
+			err = sleep(ctx, retryAfter)
+		} else {
+			err = c.wait(ctx, attempt)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// The token may have been revoked or expired early, log in again once
+	if resp.StatusCode == http.StatusUnauthorized && !req.public && !req.retried && c.canRefresh() {
+		if err := c.refresh(ctx); err != nil {
+			return nil, err
+		}
+		req.retried = true
+		req.header = header
+		return c.do(ctx, req, out)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := newAPIError(resp.StatusCode, body)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header)
+		}
+		return resp.Header, apiErr
+	}
+	if out == nil || len(body) == 0 {
+		return resp.Header, nil
+	}
+
+	var envelope struct {
+		apiModel.Response
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return resp.Header, fmt.Errorf("decode response: %w", err)
+	}
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return resp.Header, nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return resp.Header, fmt.Errorf("decode response data: %w", err)
+	}
+	return resp.Header, nil
+}
+
+// Sleep before the next attempt, exponential backoff with jitter
+func (c *Client) wait(ctx context.Context, attempt int) error {
+	delay := c.backoff << attempt
+	if delay > maxBackoff || delay <= 0 {
+		delay = maxBackoff
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+	return sleep(ctx, delay)
+}
+
+// Sleep for `delay` unless the context ends first
+func sleep(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// `Retry-After` in seconds, zero when missing or invalid
+func parseRetryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) (http.Header, error) {
+	return c.do(ctx, request{method: http.MethodGet, path: path, query: query}, out)
+}
+
+func (c *Client) send(ctx context.Context, method, path string, body any, header http.Header, out any) (http.Header, error) {
+	return c.do(ctx, request{method: method, path: path, body: body, header: header}, out)
+}
+
+// Header with `If-Match` set to the ETag, `*` without one. The API requires
+// the header on conditional writes.
+func ifMatchHeader(etag string) http.Header {
+	if etag == "" {
+		etag = "*"
+	}
+	header := http.Header{}
+	header.Set("If-Match", etag)
+	return header
+}
+
+var errNoCredentials = errors.New("client: no credentials to refresh the token, call Auth.Login first")
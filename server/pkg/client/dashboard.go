@@ -0,0 +1,21 @@
+package client
+
+import (
+	"context"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Dashboard endpoints
+type DashboardService struct {
+	client *Client
+}
+
+// Dashboard metrics of the user
+func (s *DashboardService) Get(ctx context.Context) (*transactionModels.DashboardMetrics, error) {
+	metrics := new(transactionModels.DashboardMetrics)
+	if _, err := s.client.get(ctx, "/api/dashboard", nil, metrics); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
@@ -0,0 +1,90 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+)
+
+// Error returned for a response with a 4xx or 5xx status. Match the kind of
+// failure with `errors.Is(err, client.ErrNotFound)` and read the details
+// with `errors.As`.
+type APIError struct {
+	StatusCode int
+	Code       string // Stable error code such as `VALIDATION_FAILED`
+	Message    string
+	Fields     []apiModel.FieldError // Invalid fields of the request
+	Data       json.RawMessage       // `data` of the error response, e.g. duplicate candidates
+	RetryAfter time.Duration         // When rate limited, the wait until the next request is allowed
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("api: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("api: %d %s", e.StatusCode, e.Message)
+}
+
+// Matches the status error of its status code, any 5xx matches `ErrServer`
+func (e *APIError) Is(target error) bool {
+	status, ok := target.(statusError)
+	if !ok {
+		return false
+	}
+	if status == ErrServer {
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+	return int(status) == e.StatusCode
+}
+
+// Decode `data` of the error response into `v`
+func (e *APIError) DecodeData(v any) error {
+	if len(e.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(e.Data, v)
+}
+
+// Kind of an API error, compared with `errors.Is`
+type statusError int
+
+func (s statusError) Error() string {
+	if s == ErrServer {
+		return "api: server error"
+	}
+	return fmt.Sprintf("api: %s", http.StatusText(int(s)))
+}
+
+const (
+	ErrBadRequest           = statusError(http.StatusBadRequest)           // Invalid request body or parameters
+	ErrUnauthorized         = statusError(http.StatusUnauthorized)         // Missing, expired or wrong credentials
+	ErrForbidden            = statusError(http.StatusForbidden)            // Role in the ledger is too low
+	ErrNotFound             = statusError(http.StatusNotFound)             // No such resource for the user
+	ErrConflict             = statusError(http.StatusConflict)             // Duplicate, already exists or in use
+	ErrPreconditionFailed   = statusError(http.StatusPreconditionFailed)   // `If-Match` no longer matches
+	ErrPreconditionRequired = statusError(http.StatusPreconditionRequired) // `If-Match` is missing
+	ErrUnprocessable        = statusError(http.StatusUnprocessableEntity)  // Bulk request rolled back
+	ErrTooManyRequests      = statusError(http.StatusTooManyRequests)      // Rate limited, see `APIError.RetryAfter`
+	ErrServer               = statusError(http.StatusInternalServerError)  // Any 5xx after all retries
+)
+
+// Error of a response, `body` is the API envelope when the server sent one
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode}
+	var envelope struct {
+		Code    string                `json:"code"`
+		Message string                `json:"message"`
+		Data    json.RawMessage       `json:"data"`
+		Errors  []apiModel.FieldError `json:"errors"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.Code, apiErr.Message, apiErr.Fields = envelope.Code, envelope.Message, envelope.Errors
+		if string(envelope.Data) != "null" {
+			apiErr.Data = envelope.Data
+		}
+	}
+	return apiErr
+}
@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Transaction endpoints. Writes return the ETag of the transaction, pass it
+// as `ifMatch` to fail with `ErrPreconditionFailed` when someone changed the
+// transaction in between. An empty `ifMatch` sends `*` and updates
+// unconditionally.
+type TransactionsService struct {
+	client *Client
+}
+
+// Filters for listing transactions, the zero value lists everything
+type ListOptions struct {
+	Tags     []string
+	MatchAll bool // All tags must match instead of any
+	LedgerID uint
+}
+
+// Transactions of the user's ledgers
+func (s *TransactionsService) List(ctx context.Context, opts *ListOptions) ([]transactionModels.Transaction, error) {
+	query := url.Values{}
+	if opts != nil {
+		if len(opts.Tags) > 0 {
+			query.Set("tags", strings.Join(opts.Tags, ","))
+			if opts.MatchAll {
+				query.Set("tag_match", "all")
+			}
+		}
+		if opts.LedgerID != 0 {
+			query.Set("ledger_id", strconv.FormatUint(uint64(opts.LedgerID), 10))
+		}
+	}
+
+	var transactions []transactionModels.Transaction
+	_, err := s.client.get(ctx, "/api/transaction", query, &transactions)
+	return transactions, err
+}
+
+// Add a transaction and return its ETag. A suspected duplicate fails with
+// `ErrConflict`, the candidates are in `APIError.Data`. `force` adds it anyway.
+func (s *TransactionsService) Add(ctx context.Context, req *transactionModels.AddTransactionRequest, force bool) (string, error) {
+	path := "/api/transaction/add"
+	if force {
+		path += "?force=true"
+	}
+	header, err := s.client.send(ctx, http.MethodPost, path, req, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("ETag"), nil
+}
+
+// Update the fields set in `req` and return the new ETag
+func (s *TransactionsService) Update(ctx context.Context, id uint, req *transactionModels.UpdateTransactionRequest, ifMatch string) (string, error) {
+	header, err := s.client.send(ctx, http.MethodPatch, fmt.Sprintf("/api/transaction/update/%d", id), req, ifMatchHeader(ifMatch), nil)
+	if err != nil {
+		return "", err
+	}
+	return header.Get("ETag"), nil
+}
+
+// Delete a transaction, both legs for a transfer
+func (s *TransactionsService) Delete(ctx context.Context, id uint, ifMatch string) error {
+	_, err := s.client.send(ctx, http.MethodDelete, fmt.Sprintf("/api/transaction/remove/%d", id), nil, ifMatchHeader(ifMatch), nil)
+	return err
+}
+
+// Restore a deleted transaction
+func (s *TransactionsService) Restore(ctx context.Context, id uint) error {
+	_, err := s.client.send(ctx, http.MethodPost, fmt.Sprintf("/api/transaction/restore/%d", id), nil, nil, nil)
+	return err
+}
+
+// Audit history of a transaction, oldest first
+func (s *TransactionsService) History(ctx context.Context, id uint) ([]transactionModels.TransactionAudit, error) {
+	var history []transactionModels.TransactionAudit
+	_, err := s.client.get(ctx, fmt.Sprintf("/api/transaction/%d/history", id), nil, &history)
+	return history, err
+}
+
+// Create, update and delete transactions in one request. A rolled back
+// atomic request fails with `ErrUnprocessable`, the results are in `APIError.Data`.
+func (s *TransactionsService) Bulk(ctx context.Context, req *transactionModels.BulkTransactionRequest) ([]transactionModels.BulkOperationResult, error) {
+	var results []transactionModels.BulkOperationResult
+	_, err := s.client.send(ctx, http.MethodPost, "/api/transaction/bulk", req, nil, &results)
+	return results, err
+}
+
+// Move money between two accounts, returns both legs
+func (s *TransactionsService) Transfer(ctx context.Context, req *transactionModels.AddTransferRequest) ([]transactionModels.Transaction, error) {
+	var legs []transactionModels.Transaction
+	_, err := s.client.send(ctx, http.MethodPost, "/api/transaction/transfer", req, nil, &legs)
+	return legs, err
+}
+
+// ETag of a listed transaction, for the `ifMatch` of a later write
+func ETag(transaction *transactionModels.Transaction) string {
+	return `"` + strconv.FormatUint(uint64(transaction.Version), 10) + `"`
+}
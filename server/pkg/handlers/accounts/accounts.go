@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	accountModels "github.com/niko-2609/tracker-expense/models/account"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+// Fetch all accounts of the user with their current balance
+func GetAccountsHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the account")
+	}
+
+	balances, err := utils.GetAccountBalances(database.DB.WithContext(c.UserContext()), userID)
+	if err != nil {
+		return apierror.Internal(err, "Cannot fetch accounts")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    balances,
+	})
+}
+
+// Adds a new account for the user
+func AddAccountHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the account")
+	}
+
+	addAccountReq := new(accountModels.AddAccountRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, addAccountReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	account := &accountModels.Account{
+		UserID:         userID,
+		Name:           addAccountReq.Name,
+		AccountType:    addAccountReq.AccountType,
+		Currency:       addAccountReq.Currency,
+		OpeningBalance: addAccountReq.OpeningBalance,
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Create(account).Error; err != nil {
+		return apierror.Internal(err, "Unable to add account, please try again")
+	}
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Account added successfully",
+		Data:    account,
+	})
+}
+
+// Updates name, type, currency or opening balance of an account
+func UpdateAccountHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the account")
+	}
+
+	accountID := c.Params("id")
+	patchAccountReq := new(accountModels.UpdateAccountRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, patchAccountReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	patchMap := make(map[string]any)
+	if patchAccountReq.Name != nil {
+		patchMap["name"] = patchAccountReq.Name
+	}
+	if patchAccountReq.AccountType != nil {
+		patchMap["account_type"] = patchAccountReq.AccountType
+	}
+	if patchAccountReq.Currency != nil {
+		patchMap["currency"] = patchAccountReq.Currency
+	}
+	if patchAccountReq.OpeningBalance != nil {
+		patchMap["opening_balance"] = patchAccountReq.OpeningBalance
+	}
+	if len(patchMap) == 0 {
+		return apierror.BadRequest("Atleast 1 items is required for PATCH")
+	}
+
+	tx := database.DB.WithContext(c.UserContext()).Model(&accountModels.Account{}).Where("id = ? AND user_id = ?", accountID, userID).Updates(patchMap)
+	if tx.Error != nil {
+		return apierror.Internal(tx.Error, "Cannot update account")
+	}
+	if tx.RowsAffected == 0 {
+		return apierror.NotFound("Account not found")
+	}
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Account updated",
+		Data:    patchMap,
+	})
+}
+
+// Removes an account, only allowed once it holds no transactions
+func DeleteAccountHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the account")
+	}
+
+	accountID := c.Params("id")
+
+	var count int64
+	if err := database.DB.WithContext(c.UserContext()).Model(&transactionModels.Transaction{}).Where("account_id = ? AND user_id = ?", accountID, userID).Count(&count).Error; err != nil {
+		return apierror.Internal(err, "Cannot delete account")
+	}
+	if count > 0 {
+		return apierror.Conflict("Account has transactions, move or remove them first")
+	}
+
+	tx := database.DB.WithContext(c.UserContext()).Where("id = ? AND user_id = ?", accountID, userID).Delete(&accountModels.Account{})
+	if tx.Error != nil {
+		return apierror.Internal(tx.Error, "Cannot delete account")
+	}
+	if tx.RowsAffected == 0 {
+		return apierror.NotFound("Account not found")
+	}
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Fetch the balance history of an account, `?interval=day|month` (default month)
+func GetAccountHistoryHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the account")
+	}
+
+	accountID, err := c.ParamsInt("id")
+	if err != nil || accountID <= 0 {
+		return apierror.BadRequest("Invalid account id")
+	}
+
+	account, err := utils.GetUserAccount(database.DB.WithContext(c.UserContext()), userID, uint(accountID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NotFound("Account not found")
+		}
+		return apierror.Internal(err, "Cannot fetch account history")
+	}
+
+	history, err := utils.GetBalanceHistory(database.DB.WithContext(c.UserContext()), account, c.Query("interval", "month"))
+	if err != nil {
+		return apierror.Internal(err, "Cannot fetch account history")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    history,
+	})
+}
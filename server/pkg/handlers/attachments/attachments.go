@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path/filepath"
+
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/niko-2609/tracker-expense/database"
+	attachmentModels "github.com/niko-2609/tracker-expense/models/attachment"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/storage"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+// Uploads a receipt for a transaction in one of the user's ledgers. The file is sent as
+// multipart form field `file`, its type is detected from the content.
+func UploadAttachmentHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the attachment")
+	}
+
+	transaction, err := utils.FindLedgerTransaction(database.DB.WithContext(c.UserContext()), userID, c.Params("id"), ledgerModels.RoleEditor)
+	if err != nil {
+		return transactionNotFound(c, err)
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return apierror.Invalid("file", "field is required")
+	}
+	if fileHeader.Size > attachmentModels.MaxAttachmentSize {
+		return apierror.New(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("File is too large, the limit is %d MB", attachmentModels.MaxAttachmentSize>>20))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		logs.Ctx(c).Warn("Cannot read uploaded file", "error", err)
+		return apierror.BadRequest("Cannot read uploaded file")
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, attachmentModels.MaxAttachmentSize+1))
+	if err != nil {
+		logs.Ctx(c).Warn("Cannot read uploaded file", "error", err)
+		return apierror.BadRequest("Cannot read uploaded file")
+	}
+	if len(data) > attachmentModels.MaxAttachmentSize {
+		return apierror.New(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("File is too large, the limit is %d MB", attachmentModels.MaxAttachmentSize>>20))
+	}
+
+	// Trust the content, not the name or the header sent by the client
+	contentType := mimetype.Detect(data).String()
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+	if !attachmentModels.AllowedContentTypes[contentType] {
+		return apierror.New(fiber.StatusUnsupportedMediaType, "Only JPEG, PNG, GIF, WebP images and PDF files can be attached")
+	}
+
+	ctx := c.UserContext()
+	blobID := uuid.NewString()
+	attachment := &attachmentModels.Attachment{
+		UserID:        userID,
+		TransactionID: transaction.ID,
+		FileName:      filepath.Base(fileHeader.Filename),
+		ContentType:   contentType,
+		Size:          int64(len(data)),
+		StorageKey:    fmt.Sprintf("users/%d/attachments/%s", userID, blobID),
+	}
+
+	if err := storage.Blobs.Put(ctx, attachment.StorageKey, bytes.NewReader(data), attachment.Size, contentType); err != nil {
+		return apierror.Internal(err, "Unable to store attachment, please try again")
+	}
+
+	// Thumbnails are best effort, the receipt is kept without one
+	if contentType != "application/pdf" {
+		if thumbnail, err := utils.MakeThumbnail(data); err != nil {
+			logs.Ctx(c).Warn("Cannot create thumbnail", "key", attachment.StorageKey, "error", err)
+		} else {
+			thumbnailKey := attachment.StorageKey + "_thumb.jpg"
+			if err := storage.Blobs.Put(ctx, thumbnailKey, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+				logs.Ctx(c).Error("Cannot store thumbnail", "key", thumbnailKey, "error", err)
+			} else {
+				attachment.ThumbnailKey = &thumbnailKey
+				attachment.HasThumbnail = true
+			}
+		}
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Create(attachment).Error; err != nil {
+		deleteBlobs(c, attachment)
+		return apierror.Internal(err, "Unable to add attachment, please try again")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Attachment added successfully",
+		Data:    attachment,
+	})
+}
+
+// Lists the receipts attached to a transaction in one of the user's ledgers
+func GetAttachmentsHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the attachment")
+	}
+
+	transaction, err := utils.FindLedgerTransaction(database.DB.WithContext(c.UserContext()), userID, c.Params("id"), ledgerModels.RoleViewer)
+	if err != nil {
+		return transactionNotFound(c, err)
+	}
+
+	var attachments []attachmentModels.Attachment
+	if err := database.DB.WithContext(c.UserContext()).Where("transaction_id = ?", transaction.ID).Order("id").Find(&attachments).Error; err != nil {
+		return apierror.Internal(err, "Cannot fetch attachments")
+	}
+	for i := range attachments {
+		attachments[i].HasThumbnail = attachments[i].ThumbnailKey != nil
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    attachments,
+	})
+}
+
+// Streams a receipt to any member of its ledger, or its thumbnail with `?thumbnail=true`
+func DownloadAttachmentHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the attachment")
+	}
+
+	attachment, err := getLedgerAttachment(c, userID, c.Params("id"), ledgerModels.RoleViewer)
+	if err != nil {
+		return attachmentNotFound(c, err)
+	}
+
+	key, contentType, disposition := attachment.StorageKey, attachment.ContentType, "attachment"
+	if c.QueryBool("thumbnail") {
+		if attachment.ThumbnailKey == nil {
+			return apierror.NotFound("Attachment has no thumbnail")
+		}
+		key, contentType, disposition = *attachment.ThumbnailKey, "image/jpeg", "inline"
+	}
+
+	blob, err := storage.Blobs.Get(c.UserContext(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return attachmentNotFound(c, err)
+		}
+		return apierror.Internal(err, "Cannot fetch attachment")
+	}
+
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": attachment.FileName}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	// Fiber closes the stream once the response is written
+	return c.SendStream(blob)
+}
+
+// Removes a receipt together with its stored files, editors of the ledger may do this
+func DeleteAttachmentHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the attachment")
+	}
+
+	attachment, err := getLedgerAttachment(c, userID, c.Params("id"), ledgerModels.RoleEditor)
+	if err != nil {
+		return attachmentNotFound(c, err)
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Delete(attachment).Error; err != nil {
+		return apierror.Internal(err, "Cannot delete attachment")
+	}
+	deleteBlobs(c, attachment)
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Get an attachment whose transaction is in one of the user's ledgers,
+// the user needs at least the `required` role there
+func getLedgerAttachment(c *fiber.Ctx, userID uint, attachmentID string, required string) (*attachmentModels.Attachment, error) {
+	var attachment attachmentModels.Attachment
+	if err := database.DB.WithContext(c.UserContext()).Where("id = ?", attachmentID).First(&attachment).Error; err != nil {
+		return nil, err
+	}
+	if _, err := utils.FindLedgerTransaction(database.DB.WithContext(c.UserContext()).Unscoped(), userID, attachment.TransactionID, required); err != nil {
+		return nil, err
+	}
+	attachment.HasThumbnail = attachment.ThumbnailKey != nil
+	return &attachment, nil
+}
+
+func transactionNotFound(c *fiber.Ctx, err error) error {
+	if errors.Is(err, utils.ErrLedgerForbidden) {
+		return err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		logs.Ctx(c).Error("Cannot find transaction", "error", err)
+	}
+	return apierror.NotFound("Transaction not found")
+}
+
+func attachmentNotFound(c *fiber.Ctx, err error) error {
+	if errors.Is(err, utils.ErrLedgerForbidden) {
+		return err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) && !errors.Is(err, storage.ErrNotFound) {
+		logs.Ctx(c).Error("Cannot find attachment", "error", err)
+	}
+	return apierror.NotFound("Attachment not found")
+}
+
+// Files left behind are only logged, the DB row is what users see
+func deleteBlobs(c *fiber.Ctx, attachment *attachmentModels.Attachment) {
+	keys := []string{attachment.StorageKey}
+	if attachment.ThumbnailKey != nil {
+		keys = append(keys, *attachment.ThumbnailKey)
+	}
+	for _, key := range keys {
+		if err := storage.Blobs.Delete(c.UserContext(), key); err != nil {
+			logs.Ctx(c).Error("Cannot delete blob", "key", key, "error", err)
+		}
+	}
+}
@@ -4,10 +4,14 @@ import (
 	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
+	"github.com/niko-2609/tracker-expense/database"
 	authModel "github.com/niko-2609/tracker-expense/models/auth"
 	models "github.com/niko-2609/tracker-expense/models/auth"
 	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	"github.com/niko-2609/tracker-expense/pkg/tracing"
 	"github.com/niko-2609/tracker-expense/pkg/validation"
 	"github.com/niko-2609/tracker-expense/utils"
 	"gorm.io/gorm"
@@ -19,13 +23,7 @@ func Login(c *fiber.Ctx) error {
 
 	// Validate incoming request
 	if errs, err := validation.ValidateRequest(c, input); err != nil {
-		log.Error(err.Error())
-		errMsg := validation.CheckErrors(c, errs, err)
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: errMsg,
-			Data:    nil,
-		})
+		return apierror.Validation(errs, err)
 	}
 
 	// Retrive email and password from request
@@ -36,28 +34,20 @@ func Login(c *fiber.Ctx) error {
 	userModel, err := new(authModel.User), *new(error)
 
 	if utils.IsEmail(email) {
-		userModel, err = utils.GetUserByEmail(email)
+		userModel, err = utils.GetUserByEmail(database.DB.WithContext(c.UserContext()), email)
 	}
 
 	// If we have an error
 	if err != nil {
 		// Check if the record for the requested user exists. If not, return `unauthorized` and return 401
 		if userModel == nil && errors.Is(err, gorm.ErrRecordNotFound) {
-			log.Error("User not found in database")
-			return c.Status(fiber.StatusUnauthorized).JSON(apiModel.Response{
-				Status:  "error",
-				Message: "Invalid username or password",
-				Data:    nil,
-			})
+			logs.Ctx(c).Warn("Login failed, unknown email")
+			metrics.ObserveLogin(false)
+			return apierror.Unauthorized("Invalid username or password")
 		}
 
 		// For all other errors, return 500
-		log.Error("An unexpected error occurred")
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Internal server error",
-			Data:    nil,
-		})
+		return apierror.Internal(err, "Internal server error")
 	}
 
 	// Checks passed, user can now be processed
@@ -66,28 +56,26 @@ func Login(c *fiber.Ctx) error {
 		Username: userModel.Username,
 		Email:    userModel.Username, // gives encoded string of password
 		Password: userModel.Password,
+		Locale:   userModel.Locale,
 	}
 
 	// Check password validity
-	if !utils.CompareHash(password, usercache.Password) {
-		log.Error("Password hashes do not match")
-		return c.Status(fiber.StatusUnauthorized).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Invalid username or password",
-			Data:    nil,
-		})
+	_, span := tracing.Tracer.Start(c.UserContext(), "bcrypt.compare")
+	match := utils.CompareHash(password, usercache.Password)
+	span.End()
+	if !match {
+		logs.Ctx(c).Warn("Login failed, wrong password", "user_id", userModel.ID)
+		metrics.ObserveLogin(false)
+		return apierror.Unauthorized("Invalid username or password")
 	}
 
 	token, err := utils.CreateJWTToken(usercache)
 	if err != nil {
-		log.Error("Error creating JWT token")
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Login Failed, please try again",
-			Data:    nil,
-		})
+		return apierror.Internal(err, "Login Failed, please try again")
 	}
 
+	metrics.ObserveLogin(true)
+
 	// Construct new response payload
 	resPayload := authModel.AccessPayload{
 		Token: token,
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	authModel "github.com/niko-2609/tracker-expense/models/auth"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+)
+
+// Saves the user's preferences. The locale is carried in the token, so a new
+// token is returned for it to take effect.
+func UpdatePreferences(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the preferences")
+	}
+
+	input := new(authModel.PreferencesRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, input); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	userModel, err := utils.GetUserByID(database.DB.WithContext(c.UserContext()), userID)
+	if err != nil {
+		return apierror.Internal(err, "Unable to update preferences, please try again")
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Model(userModel).Update("locale", input.Locale).Error; err != nil {
+		return apierror.Internal(err, "Unable to update preferences, please try again")
+	}
+
+	token, err := utils.CreateJWTToken(authModel.UserCache{
+		ID:       userModel.ID,
+		Username: userModel.Username,
+		Email:    userModel.Email,
+		Locale:   input.Locale,
+	})
+	if err != nil {
+		return apierror.Internal(err, "Unable to update preferences, please try again")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Preferences updated",
+		Data:    authModel.AccessPayload{Token: token},
+	})
+}
@@ -4,10 +4,11 @@ import (
 	"errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
 	"github.com/niko-2609/tracker-expense/database"
 	authmodel "github.com/niko-2609/tracker-expense/models/auth"
 	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/tracing"
 	"github.com/niko-2609/tracker-expense/pkg/validation"
 	"github.com/niko-2609/tracker-expense/utils"
 	"gorm.io/gorm"
@@ -18,13 +19,7 @@ func SignUp(c *fiber.Ctx) error {
 
 	// Validate incoming request
 	if errs, err := validation.ValidateRequest(c, input); err != nil {
-		log.Error(err.Error())
-		errMsg := validation.CheckErrors(c, errs, err)
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: errMsg,
-			Data:    nil,
-		})
+		return apierror.Validation(errs, err)
 	}
 
 	email := input.Email
@@ -33,7 +28,7 @@ func SignUp(c *fiber.Ctx) error {
 	var userModel *authmodel.User
 
 	// Check if user exists in DB
-	userModel, err := utils.GetUserByEmail(email)
+	userModel, err := utils.GetUserByEmail(database.DB.WithContext(c.UserContext()), email)
 
 	if err != nil {
 		// For all other errors that `RecordNotFound`, we return an error.
@@ -41,43 +36,25 @@ func SignUp(c *fiber.Ctx) error {
 		// Since we will proceed if a record for the user does not exist in DB.
 		if userModel == nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 			// For all other errors, return 500
-			log.Error("An unexpected error occurred")
-			return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-				Status:  "error",
-				Message: "Internal server error",
-				Data:    nil,
-			})
+			return apierror.Internal(err, "Internal server error")
 		}
 	}
 
 	// If no errors and a valid user is present, return 409
 	if userModel != nil {
-		log.Warn("User already exists, should be redirected to /login")
-		return c.Status(fiber.StatusConflict).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "User exists, try signing in",
-			Data:    nil,
-		})
+		return apierror.Conflict("User exists, try signing in")
 	}
 
 	if !utils.IsEmail(email) {
-		log.Error("Email provided is not valid")
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Invalid email or password, please try again",
-			Data:    nil,
-		})
+		return apierror.BadRequest("Invalid email or password, please try again")
 	}
 
 	//  Encrypt password
+	_, span := tracing.Tracer.Start(c.UserContext(), "bcrypt.hash")
 	hashedPass, err := utils.HashPassword(password)
+	span.End()
 	if err != nil {
-		log.Error("Error encrypting password")
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Unable to sign up user, please try again",
-			Data:    nil,
-		})
+		return apierror.Internal(err, "Unable to sign up user, please try again")
 	}
 
 	// // Get Username from Email
@@ -98,13 +75,8 @@ func SignUp(c *fiber.Ctx) error {
 	}
 
 	// Save user to DB
-	if err := database.DB.Create(userModel).Error; err != nil {
-		log.Error("Unable to create user in DB")
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Internal server error, please try again",
-			Data:    nil,
-		})
+	if err := database.DB.WithContext(c.UserContext()).Create(userModel).Error; err != nil {
+		return apierror.Internal(err, "Internal server error, please try again")
 	}
 
 	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
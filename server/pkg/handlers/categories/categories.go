@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/utils"
+)
+
+// Fetch all categories, clients resolve category names with it
+func GetCategoriesHandler(c *fiber.Ctx) error {
+	if _, err := utils.GetUserId(c); err != nil {
+		return apierror.Unauthorized("User id is required for the categories")
+	}
+
+	var categories []transactionModels.CategorySummary
+	result := database.DB.WithContext(c.UserContext()).Table("categories").
+		Select("id, name, type").
+		Order("type, name").
+		Scan(&categories)
+	if result.Error != nil {
+		return apierror.Internal(result.Error, "Cannot fetch categories")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    categories,
+	})
+}
@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+// Fetch the precomputed dashboard metrics for the user
+func GetDashboardHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the dashboard")
+	}
+
+	var metrics transactionModels.DashboardMetrics
+	result := database.DB.WithContext(c.UserContext()).Where("user_id = ?", userID).First(&metrics)
+	if result.Error != nil {
+		// No transactions yet, return empty metrics
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+				Status:  "success",
+				Message: "Operation successfull",
+				Data:    transactionModels.DashboardMetrics{UserID: userID},
+			})
+		}
+		return apierror.Internal(result.Error, "Cannot fetch dashboard")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    metrics,
+	})
+}
@@ -0,0 +1,332 @@
+package handlers
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	goalModels "github.com/niko-2609/tracker-expense/models/goal"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// Returned when the goal does not belong to the user.
+	errGoalNotFound = errors.New("goal not found")
+	// Returned when the linked transaction is not one of the goal owner's.
+	errTransactionNotFound = errors.New("transaction not found")
+	// Returned when money is moved to a goal that has no account.
+	errGoalWithoutAccount = errors.New("goal has no linked account")
+	// Returned when an expense is linked to a goal, spending never saves towards it.
+	errNotAContribution = errors.New("transaction is not a contribution")
+)
+
+// Fetch all savings goals of the user
+func GetGoalsHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the goal")
+	}
+
+	var goals []goalModels.SavingsGoal
+	if err := database.DB.WithContext(c.UserContext()).Where("user_id = ?", userID).Order("id").Find(&goals).Error; err != nil {
+		return apierror.Internal(err, "Cannot fetch goals")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    goals,
+	})
+}
+
+// Adds a new savings goal for the user
+func AddGoalHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the goal")
+	}
+
+	addGoalReq := new(goalModels.AddGoalRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, addGoalReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	if addGoalReq.AccountID != nil {
+		if _, err := utils.GetUserAccount(database.DB.WithContext(c.UserContext()), userID, *addGoalReq.AccountID); err != nil {
+			return apierror.Invalid("account_id", "account not found")
+		}
+	}
+
+	var deadline *time.Time
+	if addGoalReq.Deadline != "" {
+		date, err := time.Parse(time.DateOnly, addGoalReq.Deadline)
+		if err != nil {
+			return apierror.Invalid("deadline", "must be a date, e.g. 2026-12-31")
+		}
+		deadline = &date
+	}
+
+	goal := &goalModels.SavingsGoal{
+		UserID:       userID,
+		Name:         addGoalReq.Name,
+		TargetAmount: addGoalReq.TargetAmount,
+		Deadline:     deadline,
+		AccountID:    addGoalReq.AccountID,
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Create(goal).Error; err != nil {
+		return apierror.Internal(err, "Unable to add goal, please try again")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Goal added successfully",
+		Data:    goal,
+	})
+}
+
+// Updates a savings goal of the user
+func UpdateGoalHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the goal")
+	}
+
+	goalID := c.Params("id")
+	patchGoalReq := new(goalModels.UpdateGoalRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, patchGoalReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	patchMap := make(map[string]any)
+	if patchGoalReq.Name != nil {
+		patchMap["name"] = patchGoalReq.Name
+	}
+	if patchGoalReq.TargetAmount != nil {
+		patchMap["target_amount"] = patchGoalReq.TargetAmount
+	}
+	if patchGoalReq.Deadline != nil {
+		deadline, err := time.Parse(time.DateOnly, *patchGoalReq.Deadline)
+		if err != nil {
+			return apierror.Invalid("deadline", "must be a date, e.g. 2026-12-31")
+		}
+		patchMap["deadline"] = deadline
+	}
+	if patchGoalReq.AccountID != nil {
+		if _, err := utils.GetUserAccount(database.DB.WithContext(c.UserContext()), userID, *patchGoalReq.AccountID); err != nil {
+			return apierror.Invalid("account_id", "account not found")
+		}
+		patchMap["account_id"] = patchGoalReq.AccountID
+	}
+	if len(patchMap) == 0 {
+		return apierror.BadRequest("Atleast 1 items is required for PATCH")
+	}
+
+	tx := database.DB.WithContext(c.UserContext()).Model(&goalModels.SavingsGoal{}).Where("id = ? AND user_id = ?", goalID, userID).Updates(patchMap)
+	if tx.Error != nil {
+		return apierror.Internal(tx.Error, "Cannot update goal")
+	}
+	if tx.RowsAffected == 0 {
+		return apierror.NotFound("Goal not found")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Goal updated",
+		Data:    patchMap,
+	})
+}
+
+// Removes a savings goal, its contributions stay as regular transactions
+func DeleteGoalHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the goal")
+	}
+
+	goalID := c.Params("id")
+
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", goalID, userID).Delete(&goalModels.SavingsGoal{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errGoalNotFound
+		}
+
+		var contributionIDs []uint
+		if err := tx.Model(&transactionModels.Transaction{}).Where("goal_id = ? AND user_id = ?", goalID, userID).Pluck("id", &contributionIDs).Error; err != nil {
+			return err
+		}
+		for _, contributionID := range contributionIDs {
+			err := utils.AuditTransactionChange(tx, contributionID, transactionModels.AuditUpdate, func() error {
+				return tx.Model(&transactionModels.Transaction{}).Where("id = ?", contributionID).Updates(map[string]any{"goal_id": nil, "version": utils.BumpVersion()}).Error
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errGoalNotFound) {
+		return apierror.NotFound("Goal not found")
+	}
+	if err != nil {
+		return apierror.Internal(err, "Cannot delete goal")
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Records a contribution to a goal, either by linking an existing transaction
+// or by transferring money into the goal's account.
+func ContributeGoalHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the goal")
+	}
+
+	contributeReq := new(goalModels.ContributeRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, contributeReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	goal, err := getUserGoal(c, userID, c.Params("id"))
+	if err != nil {
+		return goalErrorResponse(c, err)
+	}
+
+	var contribution transactionModels.Transaction
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		if contributeReq.TransactionID != nil {
+			transaction, err := utils.FindLedgerTransaction(tx, userID, *contributeReq.TransactionID, ledgerModels.RoleEditor)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTransactionNotFound
+			}
+			if err != nil {
+				return err
+			}
+			// Progress only counts the goal owner's transactions, a ledger
+			// member's transaction would never show up in it
+			if transaction.UserID != goal.UserID {
+				return errTransactionNotFound
+			}
+			contribution = *transaction
+			if contribution.TxnType == transactionModels.TxnTypeExpense {
+				return errNotAContribution
+			}
+			// Only the `in` leg of a transfer counts towards the goal
+			if contribution.TransferID != nil && contribution.TransferDirection != transactionModels.TransferIn {
+				if err := tx.Where("transfer_id = ? AND transfer_direction = ?", *contribution.TransferID, transactionModels.TransferIn).First(&contribution).Error; err != nil {
+					return err
+				}
+			}
+		} else {
+			if goal.AccountID == nil {
+				return errGoalWithoutAccount
+			}
+			legs, err := utils.CreateTransfer(tx, userID, &transactionModels.AddTransferRequest{
+				FromAccountID: *contributeReq.FromAccountID,
+				ToAccountID:   *goal.AccountID,
+				Amount:        *contributeReq.Amount,
+				Name:          goal.Name,
+				Frequency:     contributeReq.Frequency,
+				Description:   contributeReq.Description,
+			})
+			if err != nil {
+				return err
+			}
+			contribution = legs[1]
+		}
+
+		contribution.GoalID = &goal.ID
+		return utils.AuditTransactionChange(tx, contribution.ID, transactionModels.AuditUpdate, func() error {
+			return tx.Model(&contribution).Updates(map[string]any{"goal_id": goal.ID, "version": utils.BumpVersion()}).Error
+		})
+	})
+	if err != nil {
+		return goalErrorResponse(c, err)
+	}
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Contribution added successfully",
+		Data:    contribution,
+	})
+}
+
+// Fetch the progress of a goal, the monthly contribution needed to meet the
+// deadline and the completion date projected from recent savings.
+func GetGoalProgressHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the goal")
+	}
+
+	goal, err := getUserGoal(c, userID, c.Params("id"))
+	if err != nil {
+		return goalErrorResponse(c, err)
+	}
+
+	progress, err := utils.GetGoalProgress(database.DB.WithContext(c.UserContext()), goal)
+	if err != nil {
+		return goalErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    progress,
+	})
+}
+
+func getUserGoal(c *fiber.Ctx, userID uint, goalID string) (*goalModels.SavingsGoal, error) {
+	var goal goalModels.SavingsGoal
+	if err := database.DB.WithContext(c.UserContext()).Where("id = ? AND user_id = ?", goalID, userID).First(&goal).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errGoalNotFound
+		}
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// Map goal errors to a response, unexpected errors are logged and return 500
+func goalErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errGoalNotFound):
+		return apierror.NotFound("Goal not found")
+	case errors.Is(err, errTransactionNotFound):
+		return apierror.NotFound("Transaction not found")
+	case errors.Is(err, errNotAContribution):
+		return apierror.Invalid("transaction_id", "must be an income or a transfer, not an expense")
+	case errors.Is(err, errGoalWithoutAccount):
+		return apierror.BadRequest("Goal has no linked account, link an existing transaction instead")
+	case errors.Is(err, utils.ErrAccountNotFound):
+		return apierror.Invalid("from_account_id", "account not found")
+	case errors.Is(err, utils.ErrSameAccount):
+		return apierror.Invalid("from_account_id", "cannot be the goal account")
+	case errors.Is(err, utils.ErrCurrencyMismatch):
+		return apierror.Invalid("from_account_id", "account must use the goal account currency")
+	case errors.Is(err, utils.ErrLedgerForbidden):
+		return err
+	}
+	return apierror.Internal(err, "Unable to process goal, please try again")
+}
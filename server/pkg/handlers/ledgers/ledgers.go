@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/niko-2609/tracker-expense/database"
+	authModels "github.com/niko-2609/tracker-expense/models/auth"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How long an invitation can be accepted
+const invitationTTL = 7 * 24 * time.Hour
+
+var (
+	// Returned when the invitation token is unknown, used or expired.
+	errInvitationNotFound = errors.New("invitation not found")
+	// Returned when the invitation was sent to another email.
+	errInvitationEmail = errors.New("invitation was sent to another email")
+	// Returned when the invited user already is a member.
+	errAlreadyMember = errors.New("user already is a member")
+	// Returned when the member is not part of the ledger.
+	errMemberNotFound = errors.New("member not found")
+	// Returned when the owner's membership would be changed or removed.
+	errOwnerMember = errors.New("owner membership cannot change")
+)
+
+// Fetch all ledgers the user is a member of with the user's role in each
+func GetLedgersHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	// Users created before ledgers existed get their personal ledger here
+	if _, err := utils.GetPersonalLedger(database.DB.WithContext(c.UserContext()), userID); err != nil {
+		return apierror.Internal(err, "Cannot fetch ledgers")
+	}
+
+	var ledgers []ledgerModels.LedgerSummary
+	err = database.DB.WithContext(c.UserContext()).Table("ledgers").
+		Select(`ledgers.id, ledgers.name, ledgers.owner_id, ledgers.personal, ledger_members.role,
+			(SELECT COUNT(*) FROM ledger_members m WHERE m.ledger_id = ledgers.id) AS members`).
+		Joins("JOIN ledger_members ON ledger_members.ledger_id = ledgers.id AND ledger_members.user_id = ?", userID).
+		Where("ledgers.deleted_at IS NULL").
+		Order("ledgers.personal DESC, ledgers.id").
+		Scan(&ledgers).Error
+	if err != nil {
+		return apierror.Internal(err, "Cannot fetch ledgers")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    ledgers,
+	})
+}
+
+// Adds a shared ledger owned by the user
+func AddLedgerHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	addLedgerReq := new(ledgerModels.AddLedgerRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, addLedgerReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	ledger := &ledgerModels.Ledger{
+		Name:    strings.TrimSpace(addLedgerReq.Name),
+		OwnerID: userID,
+	}
+	err = database.DB.WithContext(c.UserContext()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(ledger).Error; err != nil {
+			return err
+		}
+		return tx.Create(&ledgerModels.LedgerMember{
+			LedgerID: ledger.ID,
+			UserID:   userID,
+			Role:     ledgerModels.RoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return apierror.Internal(err, "Unable to add ledger, please try again")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Ledger added successfully",
+		Data:    ledger,
+	})
+}
+
+// Renames a ledger, only its owner may do this
+func UpdateLedgerHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	patchLedgerReq := new(ledgerModels.UpdateLedgerRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, patchLedgerReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleOwner)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	if patchLedgerReq.Name != nil {
+		ledger.Name = strings.TrimSpace(*patchLedgerReq.Name)
+		if err := database.DB.WithContext(c.UserContext()).Model(ledger).Update("name", ledger.Name).Error; err != nil {
+			return apierror.Internal(err, "Cannot update ledger")
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Ledger updated",
+		Data:    ledger,
+	})
+}
+
+// Removes a shared ledger, only allowed for its owner once it holds no transactions
+func DeleteLedgerHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleOwner)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+	if ledger.Personal {
+		return apierror.BadRequest("The personal ledger cannot be removed")
+	}
+
+	var count int64
+	if err := database.DB.WithContext(c.UserContext()).Model(&transactionModels.Transaction{}).Where("ledger_id = ?", ledger.ID).Count(&count).Error; err != nil {
+		return apierror.Internal(err, "Cannot delete ledger")
+	}
+	if count > 0 {
+		return apierror.Conflict("Ledger has transactions, move or remove them first")
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Delete(ledger).Error; err != nil {
+		return apierror.Internal(err, "Cannot delete ledger")
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Lists the members of a ledger, any member may see them
+func GetMembersHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleViewer)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	var members []ledgerModels.MemberSummary
+	err = database.DB.WithContext(c.UserContext()).Table("ledger_members").
+		Select("ledger_members.user_id, users.username, users.email, ledger_members.role, ledger_members.created_at AS joined_at").
+		Joins("JOIN users ON users.id = ledger_members.user_id").
+		Where("ledger_members.ledger_id = ?", ledger.ID).
+		Order("ledger_members.created_at, ledger_members.user_id").
+		Scan(&members).Error
+	if err != nil {
+		return apierror.Internal(err, "Cannot fetch members")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    members,
+	})
+}
+
+// Invites a user by email to join the ledger, only its owner may do this.
+// The returned token is shared with the invited user to accept the invitation.
+func InviteMemberHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	inviteReq := new(ledgerModels.InviteMemberRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, inviteReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleOwner)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+	if ledger.Personal {
+		return apierror.BadRequest("The personal ledger cannot be shared")
+	}
+
+	email := strings.ToLower(strings.TrimSpace(inviteReq.Email))
+
+	var count int64
+	err = database.DB.WithContext(c.UserContext()).Table("ledger_members").
+		Joins("JOIN users ON users.id = ledger_members.user_id").
+		Where("ledger_members.ledger_id = ? AND LOWER(users.email) = ?", ledger.ID, email).
+		Count(&count).Error
+	if err != nil {
+		return apierror.Internal(err, "Unable to invite member, please try again")
+	}
+	if count > 0 {
+		return ledgerErrorResponse(c, errAlreadyMember)
+	}
+
+	invitation := &ledgerModels.LedgerInvitation{
+		LedgerID:  ledger.ID,
+		Email:     email,
+		Role:      inviteReq.Role,
+		Token:     uuid.NewString(),
+		InvitedBy: userID,
+		ExpiresAt: time.Now().Add(invitationTTL),
+	}
+	if err := database.DB.WithContext(c.UserContext()).Create(invitation).Error; err != nil {
+		return apierror.Internal(err, "Unable to invite member, please try again")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Invitation created",
+		Data:    invitation,
+	})
+}
+
+// Joins the ledger of an invitation, the user's email must match the invited one
+func AcceptInvitationHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	token, err := uuid.Parse(c.Params("token"))
+	if err != nil {
+		return ledgerErrorResponse(c, errInvitationNotFound)
+	}
+
+	var member ledgerModels.LedgerMember
+	err = database.DB.WithContext(c.UserContext()).Transaction(func(tx *gorm.DB) error {
+		var invitation ledgerModels.LedgerInvitation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token = ? AND accepted_at IS NULL AND expires_at > ?", token.String(), time.Now()).
+			First(&invitation).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errInvitationNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var user authModels.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		if !strings.EqualFold(user.Email, invitation.Email) {
+			return errInvitationEmail
+		}
+
+		// The ledger may have been removed since the invitation was sent
+		var ledger ledgerModels.Ledger
+		if err := tx.First(&ledger, invitation.LedgerID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errInvitationNotFound
+			}
+			return err
+		}
+
+		member = ledgerModels.LedgerMember{
+			LedgerID: invitation.LedgerID,
+			UserID:   userID,
+			Role:     invitation.Role,
+		}
+		created := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member)
+		if created.Error != nil {
+			return created.Error
+		}
+		if created.RowsAffected == 0 {
+			return errAlreadyMember
+		}
+
+		return tx.Model(&invitation).Update("accepted_at", time.Now()).Error
+	})
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Invitation accepted",
+		Data:    member,
+	})
+}
+
+// Changes the role of a member, only the owner may do this
+func UpdateMemberHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	patchMemberReq := new(ledgerModels.UpdateMemberRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, patchMemberReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleOwner)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	member, err := getMember(c, ledger, c.Params("userId"))
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	member.Role = patchMemberReq.Role
+	err = database.DB.WithContext(c.UserContext()).Model(&ledgerModels.LedgerMember{}).
+		Where("ledger_id = ? AND user_id = ?", member.LedgerID, member.UserID).
+		Update("role", member.Role).Error
+	if err != nil {
+		return apierror.Internal(err, "Cannot update member")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Member updated",
+		Data:    member,
+	})
+}
+
+// Removes a member from the ledger. The owner may remove anyone else and
+// members may remove themselves to leave the ledger.
+func RemoveMemberHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	required := ledgerModels.RoleOwner
+	if memberID, err := c.ParamsInt("userId"); err == nil && uint(memberID) == userID {
+		required = ledgerModels.RoleViewer
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), required)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	member, err := getMember(c, ledger, c.Params("userId"))
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	// Shared expenses must be settled before leaving
+	balance, err := utils.GetMemberBalance(database.DB.WithContext(c.UserContext()), ledger.ID, member.UserID)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+	if balance != 0 {
+		return ledgerErrorResponse(c, errOpenBalance)
+	}
+
+	err = database.DB.WithContext(c.UserContext()).Where("ledger_id = ? AND user_id = ?", member.LedgerID, member.UserID).
+		Delete(&ledgerModels.LedgerMember{}).Error
+	if err != nil {
+		return apierror.Internal(err, "Cannot remove member")
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Get a ledger where the user has at least the `required` role
+func getLedger(c *fiber.Ctx, userID uint, ledgerID string, required string) (*ledgerModels.Ledger, error) {
+	var ledger ledgerModels.Ledger
+	if err := database.DB.WithContext(c.UserContext()).Where("id = ?", ledgerID).First(&ledger).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, utils.ErrLedgerNotFound
+		}
+		return nil, err
+	}
+	if err := utils.CheckLedgerRole(database.DB.WithContext(c.UserContext()), ledger.ID, userID, required); err != nil {
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+// Get a member of the ledger other than its owner
+func getMember(c *fiber.Ctx, ledger *ledgerModels.Ledger, memberID string) (*ledgerModels.LedgerMember, error) {
+	var member ledgerModels.LedgerMember
+	if err := database.DB.WithContext(c.UserContext()).Where("ledger_id = ? AND user_id = ?", ledger.ID, memberID).First(&member).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errMemberNotFound
+		}
+		return nil, err
+	}
+	if member.Role == ledgerModels.RoleOwner {
+		return nil, errOwnerMember
+	}
+	return &member, nil
+}
+
+// Map ledger errors to a response, unexpected errors are logged and return 500
+func ledgerErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, utils.ErrLedgerNotFound), errors.Is(err, utils.ErrLedgerForbidden):
+		return err // Mapped by apierror.Handler
+	case errors.Is(err, errMemberNotFound):
+		return apierror.NotFound("Member not found")
+	case errors.Is(err, errOwnerMember):
+		return apierror.BadRequest("The owner's membership cannot be changed or removed")
+	case errors.Is(err, errAlreadyMember):
+		return apierror.Conflict("User already is a member of this ledger")
+	case errors.Is(err, errOpenBalance):
+		return apierror.Conflict("Member has an open balance, settle up first")
+	case errors.Is(err, errInvitationNotFound):
+		return apierror.NotFound("Invitation not found or expired")
+	case errors.Is(err, errInvitationEmail):
+		return apierror.Forbidden("Invitation was sent to another email")
+	}
+	return apierror.Internal(err, "Unable to process ledger, please try again")
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+)
+
+// Returned when a member with an open balance would leave the ledger.
+var errOpenBalance = errors.New("member has an open balance")
+
+// Shows who owes whom in the ledger and the fewest payments that settle everyone up
+func GetBalancesHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleViewer)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	balances, err := utils.GetLedgerBalances(database.DB.WithContext(c.UserContext()), ledger.ID)
+	if err != nil {
+		return apierror.Internal(err, "Cannot fetch balances")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    balances,
+	})
+}
+
+// Lists the settlements recorded in the ledger, newest first
+func GetSettlementsHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	ledger, err := getLedger(c, userID, c.Params("id"), ledgerModels.RoleViewer)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	var settlements []ledgerModels.Settlement
+	if err := database.DB.WithContext(c.UserContext()).Where("ledger_id = ?", ledger.ID).Order("created_at DESC, id DESC").Find(&settlements).Error; err != nil {
+		return apierror.Internal(err, "Cannot fetch settlements")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    settlements,
+	})
+}
+
+// Records a payment from one member to another. Members record payments they
+// made or received, the owner may record any payment between members.
+func AddSettlementHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the ledger")
+	}
+
+	settlementReq := new(ledgerModels.AddSettlementRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, settlementReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	fromUserID := settlementReq.FromUserID
+	if fromUserID == 0 {
+		fromUserID = userID
+	}
+	if fromUserID == settlementReq.ToUserID {
+		return apierror.Invalid("to_user_id", "must differ from the paying member")
+	}
+
+	required := ledgerModels.RoleViewer
+	if fromUserID != userID && settlementReq.ToUserID != userID {
+		required = ledgerModels.RoleOwner
+	}
+	ledger, err := getLedger(c, userID, c.Params("id"), required)
+	if err != nil {
+		return ledgerErrorResponse(c, err)
+	}
+
+	for _, memberID := range []uint{fromUserID, settlementReq.ToUserID} {
+		if _, err := utils.GetLedgerRole(database.DB.WithContext(c.UserContext()), ledger.ID, memberID); err != nil {
+			if errors.Is(err, utils.ErrLedgerNotFound) {
+				return ledgerErrorResponse(c, errMemberNotFound)
+			}
+			return ledgerErrorResponse(c, err)
+		}
+	}
+
+	settlement := &ledgerModels.Settlement{
+		LedgerID:   ledger.ID,
+		FromUserID: fromUserID,
+		ToUserID:   settlementReq.ToUserID,
+		Amount:     settlementReq.Amount,
+		Note:       settlementReq.Note,
+		CreatedBy:  userID,
+	}
+	if err := database.DB.WithContext(c.UserContext()).Create(settlement).Error; err != nil {
+		return apierror.Internal(err, "Unable to record settlement, please try again")
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Settlement recorded",
+		Data:    settlement,
+	})
+}
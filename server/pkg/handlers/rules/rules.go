@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ruleModels "github.com/niko-2609/tracker-expense/models/rule"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/rules"
+	"github.com/niko-2609/tracker-expense/pkg/suggest"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/datatypes"
+)
+
+// Fetch all categorization rules of the user in the order they run
+func GetRulesHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the rule")
+	}
+
+	var ruleset []ruleModels.CategorizationRule
+	if err := database.DB.WithContext(c.UserContext()).Where("user_id = ?", userID).Order("priority, id").Find(&ruleset).Error; err != nil {
+		return apierror.Internal(err, "Cannot fetch rules")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    ruleset,
+	})
+}
+
+// Adds a new categorization rule for the user
+func AddRuleHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the rule")
+	}
+
+	addRuleReq := new(ruleModels.AddRuleRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, addRuleReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	if err := rules.ValidatePattern(addRuleReq.MatchType, addRuleReq.Pattern); err != nil {
+		return apierror.Invalid("pattern", err.Error())
+	}
+
+	if addRuleReq.SetCategoryID == nil && addRuleReq.SetName == nil && len(addRuleReq.SetTags) == 0 {
+		return apierror.BadRequest("Invalid request - a rule must set a category, tags or a name")
+	}
+
+	enabled := true
+	if addRuleReq.Enabled != nil {
+		enabled = *addRuleReq.Enabled
+	}
+
+	rule := &ruleModels.CategorizationRule{
+		UserID:        userID,
+		Name:          addRuleReq.Name,
+		Priority:      addRuleReq.Priority,
+		Enabled:       enabled,
+		MatchField:    addRuleReq.MatchField,
+		MatchType:     addRuleReq.MatchType,
+		Pattern:       addRuleReq.Pattern,
+		MinAmount:     addRuleReq.MinAmount,
+		MaxAmount:     addRuleReq.MaxAmount,
+		TxnType:       addRuleReq.TxnType,
+		SetCategoryID: addRuleReq.SetCategoryID,
+		SetTags:       datatypes.NewJSONSlice(utils.NormalizeTagNames(addRuleReq.SetTags)),
+		SetName:       addRuleReq.SetName,
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Create(rule).Error; err != nil {
+		return apierror.Internal(err, "Unable to add rule, please try again")
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Rule added successfully",
+		Data:    rule,
+	})
+}
+
+// Updates a categorization rule of the user
+func UpdateRuleHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the rule")
+	}
+
+	ruleID := c.Params("id")
+	patchRuleReq := new(ruleModels.UpdateRuleRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, patchRuleReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	var rule ruleModels.CategorizationRule
+	if err := database.DB.WithContext(c.UserContext()).Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		return apierror.NotFound("Rule not found")
+	}
+
+	patchMap := buildRulePatchMap(patchRuleReq)
+	if len(patchMap) == 0 {
+		return apierror.BadRequest("Atleast 1 items is required for PATCH")
+	}
+
+	// The pattern must still be valid for the resulting match type
+	matchType, pattern := rule.MatchType, rule.Pattern
+	if patchRuleReq.MatchType != nil {
+		matchType = *patchRuleReq.MatchType
+	}
+	if patchRuleReq.Pattern != nil {
+		pattern = *patchRuleReq.Pattern
+	}
+	if err := rules.ValidatePattern(matchType, pattern); err != nil {
+		return apierror.Invalid("pattern", err.Error())
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Model(&rule).Updates(patchMap).Error; err != nil {
+		return apierror.Internal(err, "Cannot update rule")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Rule updated",
+		Data:    patchMap,
+	})
+}
+
+func buildRulePatchMap(patchReq *ruleModels.UpdateRuleRequest) map[string]any {
+	patchMap := make(map[string]any)
+	if patchReq.Name != nil {
+		patchMap["name"] = patchReq.Name
+	}
+	if patchReq.Priority != nil {
+		patchMap["priority"] = patchReq.Priority
+	}
+	if patchReq.Enabled != nil {
+		patchMap["enabled"] = patchReq.Enabled
+	}
+	if patchReq.MatchField != nil {
+		patchMap["match_field"] = patchReq.MatchField
+	}
+	if patchReq.MatchType != nil {
+		patchMap["match_type"] = patchReq.MatchType
+	}
+	if patchReq.Pattern != nil {
+		patchMap["pattern"] = patchReq.Pattern
+	}
+	if patchReq.MinAmount != nil {
+		patchMap["min_amount"] = patchReq.MinAmount
+	}
+	if patchReq.MaxAmount != nil {
+		patchMap["max_amount"] = patchReq.MaxAmount
+	}
+	if patchReq.TxnType != nil {
+		patchMap["txn_type"] = patchReq.TxnType
+	}
+	if patchReq.SetCategoryID != nil {
+		patchMap["set_category_id"] = patchReq.SetCategoryID
+	}
+	if patchReq.SetTags != nil {
+		patchMap["set_tags"] = datatypes.NewJSONSlice(utils.NormalizeTagNames(*patchReq.SetTags))
+	}
+	if patchReq.SetName != nil {
+		patchMap["set_name"] = patchReq.SetName
+	}
+	return patchMap
+}
+
+// Removes a categorization rule of the user
+func DeleteRuleHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the rule")
+	}
+
+	tx := database.DB.WithContext(c.UserContext()).Where("id = ? AND user_id = ?", c.Params("id"), userID).Delete(&ruleModels.CategorizationRule{})
+	if tx.Error != nil {
+		return apierror.Internal(tx.Error, "Cannot delete rule")
+	}
+	if tx.RowsAffected == 0 {
+		return apierror.NotFound("Rule not found")
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Re-runs the rules on the user's existing transactions. With `dry_run` the
+// changes are only reported, otherwise they are applied in one DB transaction.
+func ApplyRulesHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the rule")
+	}
+
+	applyReq := new(ruleModels.ApplyRulesRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, applyReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	changes, err := utils.ApplyRules(database.DB.WithContext(utils.AuditContext(c)), userID, utils.ApplyRulesOptions{
+		DryRun:            applyReq.DryRun,
+		TransactionIDs:    applyReq.TransactionIDs,
+		OnlyUncategorized: applyReq.OnlyUncategorized,
+	})
+	if err != nil {
+		if errors.Is(err, utils.ErrNoRules) {
+			return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+				Status:  "success",
+				Message: "No enabled rules to apply",
+				Data:    []ruleModels.RuleChange{},
+			})
+		}
+		return apierror.Internal(err, "Unable to apply rules, please try again")
+	}
+
+	message := "Rules applied"
+	if applyReq.DryRun {
+		message = "Dry run, no changes were applied"
+	} else if len(changes) > 0 {
+		// Update dashboard metrics
+		utils.UpdateDashboardMetrics(c.UserContext(), userID)
+		suggest.Enqueue(userID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: message,
+		Data:    changes,
+	})
+}
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+// Fetch all tags of the user with the number of transactions using them
+func GetTagsHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the tags")
+	}
+
+	var tags []transactionModels.TagSummary
+	result := database.DB.WithContext(c.UserContext()).Table("tags").
+		Select("tags.id, tags.name, COUNT(t.id) AS transaction_count").
+		Joins("LEFT JOIN transaction_tags tt ON tt.tag_id = tags.id").
+		Joins("LEFT JOIN transactions t ON t.id = tt.transaction_id AND t.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id, tags.name").
+		Order("tags.name").
+		Scan(&tags)
+	if result.Error != nil {
+		return apierror.Internal(result.Error, "Cannot fetch tags")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    tags,
+	})
+}
+
+// Rename a tag of the user
+func RenameTagHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the tags")
+	}
+
+	tagID := c.Params("id")
+	renameReq := new(transactionModels.RenameTagRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, renameReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	names := utils.NormalizeTagNames([]string{renameReq.Name})
+	if len(names) == 0 {
+		return apierror.Invalid("name", "field is required")
+	}
+
+	var tag transactionModels.Tag
+	if err := database.DB.WithContext(c.UserContext()).Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return apierror.NotFound("Tag not found")
+		}
+		return apierror.Internal(err, "Cannot rename tag")
+	}
+
+	// A tag with the new name already exists, the client should merge instead
+	var existing int64
+	if err := database.DB.WithContext(c.UserContext()).Model(&transactionModels.Tag{}).Where("user_id = ? AND name = ? AND id <> ?", userID, names[0], tag.ID).Count(&existing).Error; err != nil {
+		return apierror.Internal(err, "Cannot rename tag")
+	}
+	if existing > 0 {
+		return apierror.Conflict("A tag with this name exists, merge the tags instead")
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Model(&tag).Update("name", names[0]).Error; err != nil {
+		return apierror.Internal(err, "Cannot rename tag")
+	}
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Tag renamed",
+		Data:    tag,
+	})
+}
+
+// Merge source tags into a target tag. Transactions carrying any of the sources
+// get the target tag and the source tags are removed.
+func MergeTagsHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the tags")
+	}
+
+	mergeReq := new(transactionModels.MergeTagsRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, mergeReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	seen := map[uint]bool{mergeReq.TargetID: true}
+	sourceIDs := make([]uint, 0, len(mergeReq.SourceIDs))
+	for _, id := range mergeReq.SourceIDs {
+		if !seen[id] {
+			seen[id] = true
+			sourceIDs = append(sourceIDs, id)
+		}
+	}
+
+	// Every tag in the request must belong to the user
+	var owned int64
+	if err := database.DB.WithContext(c.UserContext()).Model(&transactionModels.Tag{}).Where("user_id = ? AND id IN ?", userID, append(sourceIDs, mergeReq.TargetID)).Count(&owned).Error; err != nil {
+		return apierror.Internal(err, "Cannot merge tags")
+	}
+	if len(sourceIDs) == 0 || owned != int64(len(sourceIDs)+1) {
+		return apierror.NotFound("Tag not found")
+	}
+
+	err = database.DB.WithContext(c.UserContext()).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO transaction_tags (transaction_id, tag_id)
+			SELECT DISTINCT transaction_id, ? FROM transaction_tags WHERE tag_id IN ?
+			ON CONFLICT DO NOTHING`, mergeReq.TargetID, sourceIDs).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND id IN ?", userID, sourceIDs).Delete(&transactionModels.Tag{}).Error
+	})
+	if err != nil {
+		return apierror.Internal(err, "Cannot merge tags")
+	}
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Tags merged",
+		Data:    nil,
+	})
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	"github.com/niko-2609/tracker-expense/pkg/suggest"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	// Returned when a bulk item references a transaction the user does not own.
+	errTransactionNotFound = errors.New("transaction not found")
+	// Returned when a bulk update carries no fields to change.
+	errEmptyPatch = errors.New("atleast 1 item is required for update")
+	// Returned when the account of a transaction does not belong to the user.
+	errAccountNotFound = utils.ErrAccountNotFound
+	// Returned when the ledger is not one of the user's.
+	errLedgerNotFound = utils.ErrLedgerNotFound
+	// Returned when the user's role in the ledger does not allow the change.
+	errLedgerForbidden = utils.ErrLedgerForbidden
+	// Returned when no category was picked and no rule set one.
+	errCategoryRequired = errors.New("category is required")
+	// Returned when a bulk update or delete does not say which version it expects.
+	errVersionRequired = errors.New("version is required for update and delete")
+)
+
+// Runs a batch of create, update and delete operations for the user.
+// In `atomic` mode (default) the whole batch is rolled back on the first failure,
+// in `best_effort` mode every item is applied on its own. Updates and deletes
+// must carry the `version` they expect, like `If-Match` on single writes.
+func BulkTransactionHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	bulkReq := new(transactionModels.BulkTransactionRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, bulkReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	mode := bulkReq.Mode
+	if mode == "" {
+		mode = transactionModels.BulkModeAtomic
+	}
+
+	results := make([]transactionModels.BulkOperationResult, len(bulkReq.Operations))
+	succeeded := 0
+
+	if mode == transactionModels.BulkModeAtomic {
+		failedAt := -1
+		txErr := database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+			for i, op := range bulkReq.Operations {
+				id, err := applyBulkOperation(tx, userID, op, bulkReq.Force)
+				results[i] = bulkResult(i, op, id, err)
+				if err != nil {
+					failedAt = i
+					return err
+				}
+			}
+			return nil
+		})
+
+		if txErr != nil {
+			logs.Ctx(c).Warn("Bulk operation rolled back", "index", failedAt, "error", txErr)
+			// Nothing was persisted, so mark every other item as rolled back
+			for i, op := range bulkReq.Operations {
+				if i == failedAt {
+					continue
+				}
+				results[i] = transactionModels.BulkOperationResult{
+					Index:   i,
+					Op:      op.Op,
+					ID:      results[i].ID,
+					Status:  "skipped",
+					Message: "Rolled back, another operation in the batch failed",
+				}
+			}
+			status := fiber.StatusInternalServerError
+			if failedAt >= 0 {
+				status = fiber.StatusUnprocessableEntity
+			}
+			return apierror.New(status, "Bulk operation failed, no changes were applied").WithData(results)
+		}
+		succeeded = len(bulkReq.Operations)
+	} else {
+		for i, op := range bulkReq.Operations {
+			// Each item runs in its own DB transaction so a failure leaves no partial row behind
+			var id uint
+			err := database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+				var err error
+				id, err = applyBulkOperation(tx, userID, op, bulkReq.Force)
+				return err
+			})
+			if err != nil {
+				logs.Ctx(c).Warn("Bulk operation failed", "index", i, "error", err)
+			} else {
+				succeeded++
+			}
+			results[i] = bulkResult(i, op, id, err)
+		}
+	}
+
+	// Update dashboard metrics once for the whole batch, also of the owners
+	// and members holding a share of changed transactions
+	if succeeded > 0 {
+		var changedIDs []any
+		for i, result := range results {
+			if result.Status != "success" {
+				continue
+			}
+			if result.Op == transactionModels.BulkOpCreate {
+				metrics.TransactionsCreated(bulkReq.Operations[i].Create.TxnType, 1)
+				continue
+			}
+			changedIDs = append(changedIDs, result.ID)
+		}
+		var ownerIDs, shareUserIDs []uint
+		if len(changedIDs) > 0 {
+			ownerIDs, shareUserIDs = affectedUserIDs(c, changedIDs...)
+		}
+		updateMetrics(c.UserContext(), userID, append(ownerIDs, shareUserIDs...)...)
+
+		// Retrain category suggestions of the user and the owners
+		suggest.Enqueue(userID)
+		for _, ownerID := range ownerIDs {
+			if ownerID != userID {
+				suggest.Enqueue(ownerID)
+			}
+		}
+	}
+
+	status, message := fiber.StatusOK, "Bulk operation completed"
+	if succeeded < len(bulkReq.Operations) {
+		status, message = fiber.StatusMultiStatus, "Bulk operation completed with errors"
+	}
+
+	return c.Status(status).JSON(apiModel.Response{
+		Status:  "success",
+		Message: message,
+		Data:    results,
+	})
+}
+
+// Applies a single bulk operation using the given DB handle.
+// Update and delete only touch rows owned by `userID`.
+func applyBulkOperation(db *gorm.DB, userID uint, op transactionModels.BulkOperation, force bool) (uint, error) {
+	switch op.Op {
+	case transactionModels.BulkOpCreate:
+		transaction, err := createTransaction(db, userID, op.Create, force)
+		if err != nil {
+			return 0, err
+		}
+		return transaction.ID, nil
+
+	case transactionModels.BulkOpUpdate:
+		if op.Version == 0 {
+			return *op.ID, errVersionRequired
+		}
+		patchMap := buildPatchMap(op.Update)
+		if len(patchMap) == 0 && op.Update.Tags == nil && op.Update.Splits == nil {
+			return *op.ID, errEmptyPatch
+		}
+		_, err := updateTransaction(db, userID, *op.ID, patchMap, op.Update, expectedVersions{op.Version})
+		return *op.ID, err
+
+	case transactionModels.BulkOpDelete:
+		if op.Version == 0 {
+			return *op.ID, errVersionRequired
+		}
+		return *op.ID, deleteTransaction(db, userID, *op.ID, expectedVersions{op.Version})
+	}
+
+	return 0, fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// Builds the per-item result returned to the client.
+func bulkResult(index int, op transactionModels.BulkOperation, id uint, err error) transactionModels.BulkOperationResult {
+	result := transactionModels.BulkOperationResult{
+		Index:  index,
+		Op:     op.Op,
+		ID:     id,
+		Status: "success",
+	}
+	if err != nil {
+		result.Status = "error"
+		var dupErr *duplicateError
+		switch {
+		case errors.As(err, &dupErr):
+			result.Code, result.Message = apierror.CodeConflict, "Possible duplicate transaction, send force to add it anyway"
+			for _, candidate := range dupErr.Candidates {
+				result.Duplicates = append(result.Duplicates, candidate.ID)
+			}
+		case errors.Is(err, errTransactionNotFound):
+			result.Code, result.Message = apierror.CodeNotFound, "Transaction not found"
+		case errors.Is(err, errVersionMismatch):
+			result.Code, result.Message = apierror.CodePreconditionFailed, "Transaction was changed by another request, version does not match"
+		case errors.Is(err, errVersionRequired):
+			result.Code, result.Message = apierror.CodeValidationFailed, "Version is required for update and delete"
+		case errors.Is(err, errEmptyPatch):
+			result.Code, result.Message = apierror.CodeValidationFailed, "Atleast 1 item is required for update"
+		case errors.Is(err, errSplitMismatch):
+			result.Code, result.Message = apierror.CodeValidationFailed, "Split amounts must add up to the transaction amount"
+		case errors.Is(err, errAccountNotFound):
+			result.Code, result.Message = apierror.CodeValidationFailed, "Account not found"
+		case errors.Is(err, errLedgerNotFound):
+			result.Code, result.Message = apierror.CodeValidationFailed, "Ledger not found"
+		case errors.Is(err, errLedgerForbidden):
+			result.Code, result.Message = apierror.CodeForbidden, apierror.From(err).Message
+		case errors.Is(err, errCategoryRequired):
+			result.Code, result.Message = apierror.CodeValidationFailed, "Category is required, no rule matched"
+		case errors.Is(err, errTransferLeg):
+			result.Code, result.Message = apierror.CodeBadRequest, "Transfers can only be changed through the transfer endpoint"
+		case errors.Is(err, errSharedExpense):
+			result.Code, result.Message = apierror.CodeBadRequest, "Remove the shares before changing the type of a shared expense"
+		default:
+			result.Code, result.Message = apierror.CodeInternal, fmt.Sprintf("Unable to %s transaction", op.Op)
+		}
+	}
+	return result
+}
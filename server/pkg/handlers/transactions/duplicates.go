@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Returned when a new transaction looks like one the user already has.
+type duplicateError struct {
+	Candidates []transactionModels.Transaction
+}
+
+func (e *duplicateError) Error() string {
+	return "possible duplicate transaction"
+}
+
+// Lists pairs of the user's transactions that look like duplicates
+func GetDuplicatesHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	pairs, err := utils.FindDuplicatePairs(database.DB.WithContext(c.UserContext()), userID)
+	if err != nil {
+		return apierror.Internal(err, "Cannot fetch duplicates")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    pairs,
+	})
+}
+
+// Marks a pair of transactions as not being duplicates
+func DismissDuplicateHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	dismissReq := new(transactionModels.DismissDuplicateRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, dismissReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	firstID, secondID := dismissReq.TransactionIDs[0], dismissReq.TransactionIDs[1]
+	if firstID > secondID {
+		firstID, secondID = secondID, firstID
+	}
+
+	// Both transactions must be in ledgers where the user may edit
+	for _, transactionID := range []uint{firstID, secondID} {
+		_, err := utils.FindLedgerTransaction(database.DB.WithContext(c.UserContext()), userID, transactionID, ledgerModels.RoleEditor)
+		if errors.Is(err, errLedgerForbidden) {
+			return err
+		}
+		if err != nil || firstID == secondID {
+			return apierror.NotFound("Transaction not found")
+		}
+	}
+
+	dismissal := transactionModels.DuplicateDismissal{
+		UserID:   userID,
+		FirstID:  firstID,
+		SecondID: secondID,
+	}
+	if err := database.DB.WithContext(c.UserContext()).Clauses(clause.OnConflict{DoNothing: true}).Create(&dismissal).Error; err != nil {
+		return apierror.Internal(err, "Cannot dismiss duplicate")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Duplicate dismissed",
+		Data:    dismissal,
+	})
+}
+
+// Merges a duplicate into the transaction that is kept. Tags and the goal link
+// move over to the kept transaction and the duplicate is removed.
+func MergeDuplicateHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	mergeReq := new(transactionModels.MergeDuplicateRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, mergeReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	var kept transactionModels.Transaction
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		var removed transactionModels.Transaction
+		for _, item := range []struct {
+			id  uint
+			dst *transactionModels.Transaction
+		}{{mergeReq.KeepID, &kept}, {mergeReq.RemoveID, &removed}} {
+			transaction, err := utils.FindLedgerTransaction(tx.Preload("Tags"), userID, item.id, ledgerModels.RoleEditor)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errTransactionNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if transaction.TxnType == transactionModels.TxnTypeTransfer {
+				return errTransferLeg
+			}
+			*item.dst = *transaction
+		}
+
+		err := utils.AuditTransactionChange(tx, kept.ID, transactionModels.AuditUpdate, func() error {
+			tagNames := make([]string, 0, len(kept.Tags)+len(removed.Tags))
+			for _, tag := range append(kept.Tags, removed.Tags...) {
+				tagNames = append(tagNames, tag.Name)
+			}
+			if err := utils.SetTransactionTags(tx, &kept, tagNames); err != nil {
+				return err
+			}
+
+			keptPatch := map[string]any{"version": utils.BumpVersion()}
+			if kept.GoalID == nil && removed.GoalID != nil {
+				keptPatch["goal_id"] = removed.GoalID
+			}
+			return tx.Model(&kept).Updates(keptPatch).Error
+		})
+		if err != nil {
+			return err
+		}
+
+		return deleteTransaction(tx, userID, removed.ID, nil)
+	})
+	if errors.Is(err, errLedgerForbidden) {
+		return err
+	}
+	if errors.Is(err, errTransactionNotFound) {
+		return apierror.NotFound("Transaction not found")
+	}
+	if errors.Is(err, errTransferLeg) {
+		return apierror.BadRequest("Transfers cannot be merged")
+	}
+	if err != nil {
+		return apierror.Internal(err, "Cannot merge duplicate")
+	}
+
+	// Update dashboard metrics, also of the owners and members holding a share
+	ownerIDs, shareUserIDs := affectedUserIDs(c, mergeReq.KeepID, mergeReq.RemoveID)
+	updateMetrics(c.UserContext(), userID, append(ownerIDs, shareUserIDs...)...)
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Duplicate merged",
+		Data:    kept,
+	})
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+)
+
+// Returned when `If-Match` does not match the current version of a transaction.
+var errVersionMismatch = errors.New("transaction version does not match")
+
+// ETag of a transaction, its version as a strong validator
+func transactionETag(version uint) string {
+	return `"` + strconv.FormatUint(uint64(version), 10) + `"`
+}
+
+// Versions of a transaction a write may apply to, empty allows any version
+type expectedVersions []uint
+
+func (v expectedVersions) allows(version uint) bool {
+	return len(v) == 0 || slices.Contains(v, version)
+}
+
+// Versions the client expects from the `If-Match` header, a comma separated
+// list of ETags. The header is required so concurrent writes never overwrite
+// each other unnoticed, `*` applies the write to any version. Values that
+// are not one of our ETags can never match.
+func ifMatchVersions(c *fiber.Ctx) (expectedVersions, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" {
+		return nil, apierror.New(fiber.StatusPreconditionRequired, "If-Match is required, send the ETag of the transaction or `*`")
+	}
+	if header == "*" {
+		return nil, nil
+	}
+
+	var versions expectedVersions
+	for _, etag := range strings.Split(header, ",") {
+		etag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(etag), "W/"), `"`)
+		version, err := strconv.ParseUint(etag, 10, 64)
+		if err == nil && version != 0 {
+			versions = append(versions, uint(version))
+		}
+	}
+	if len(versions) == 0 {
+		return nil, preconditionFailed()
+	}
+	return versions, nil
+}
+
+// Response for a failed `If-Match`
+func preconditionFailed() error {
+	return apierror.PreconditionFailed("Transaction was changed by another request, fetch it again and retry")
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+)
+
+// Fetch the audit history of a transaction, oldest change first. Any member
+// of the transaction's ledger can read it, deleted transactions keep their history.
+func GetTransactionHistoryHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	if _, err := utils.FindLedgerTransaction(database.DB.WithContext(c.UserContext()).Unscoped(), userID, c.Params("id"), ledgerModels.RoleViewer); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logs.Ctx(c).Error("Cannot find transaction", "error", err)
+		}
+		return apierror.NotFound("Transaction not found")
+	}
+
+	var history []transactionModels.TransactionAudit
+	if err := database.DB.WithContext(c.UserContext()).Where("transaction_id = ?", c.Params("id")).Order("id").Find(&history).Error; err != nil {
+		return apierror.Internal(err, "Cannot fetch transaction history")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    history,
+	})
+}
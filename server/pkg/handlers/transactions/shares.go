@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// Returned when a share is given to a user outside the ledger.
+	errShareNotMember = errors.New("share member is not in the ledger")
+	// Returned when shares are set on something other than an expense,
+	// or a shared expense would stop being one.
+	errSharedExpense = errors.New("only expenses can be shared")
+)
+
+// Lists how an expense is split between the members of its ledger
+func GetSharesHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	transaction, err := utils.FindLedgerTransaction(database.DB.WithContext(c.UserContext()), userID, c.Params("id"), ledgerModels.RoleViewer)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return apierror.NotFound("Transaction not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	var shares []transactionModels.ExpenseShare
+	if err := database.DB.WithContext(c.UserContext()).Where("transaction_id = ?", transaction.ID).Order("user_id").Find(&shares).Error; err != nil {
+		return apierror.Internal(err, "Cannot fetch shares")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    shares,
+	})
+}
+
+// Splits an expense between members of its ledger equally, by exact amounts,
+// by percentage or by shares. The transaction's user paid and is owed the
+// other members' shares. Replaces an earlier split.
+func ShareExpenseHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	shareReq := new(transactionModels.ShareExpenseRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, shareReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	// `If-Match` with the ETag the client last saw
+	expected, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var shares []transactionModels.ExpenseShare
+	var previous []uint
+	var version uint
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockTransaction(tx, userID, c.Params("id"), expected)
+		if err != nil {
+			return err
+		}
+		if transaction.TxnType != transactionModels.TxnTypeExpense {
+			return errSharedExpense
+		}
+
+		for _, member := range shareReq.Members {
+			if _, err := utils.GetLedgerRole(tx, transaction.LedgerID, member.UserID); err != nil {
+				if errors.Is(err, utils.ErrLedgerNotFound) {
+					return fmt.Errorf("%w: user %d", errShareNotMember, member.UserID)
+				}
+				return err
+			}
+		}
+
+		shares, err = utils.ComputeShares(shareReq.Method, transaction.Amount, shareReq.Members)
+		if err != nil {
+			return err
+		}
+
+		if previous, err = utils.ExpenseShareUserIDs(tx, transaction.ID); err != nil {
+			return err
+		}
+
+		err = utils.AuditTransactionChange(tx, transaction.ID, transactionModels.AuditUpdate, func() error {
+			if err := utils.SetExpenseShares(tx, transaction.ID, shares); err != nil {
+				return err
+			}
+			return bumpVersion(tx, transaction)
+		})
+		version = transaction.Version
+		return err
+	})
+	if err != nil {
+		return shareErrorResponse(c, err)
+	}
+
+	// Shares change the category totals of the owner and every member involved
+	ownerIDs, _ := affectedUserIDs(c, c.Params("id"))
+	for _, share := range shares {
+		previous = append(previous, share.UserID)
+	}
+	updateMetrics(c.UserContext(), userID, append(ownerIDs, previous...)...)
+
+	c.Set(fiber.HeaderETag, transactionETag(version))
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Expense shared",
+		Data:    shares,
+	})
+}
+
+// Stops splitting an expense, it counts fully for the member who paid again
+func UnshareExpenseHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	// `If-Match` with the ETag the client last saw
+	expected, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var previous []uint
+	var version uint
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockTransaction(tx, userID, c.Params("id"), expected)
+		if err != nil {
+			return err
+		}
+		if previous, err = utils.ExpenseShareUserIDs(tx, transaction.ID); err != nil || len(previous) == 0 {
+			version = transaction.Version
+			return err
+		}
+
+		err = utils.AuditTransactionChange(tx, transaction.ID, transactionModels.AuditUpdate, func() error {
+			if err := utils.SetExpenseShares(tx, transaction.ID, nil); err != nil {
+				return err
+			}
+			return bumpVersion(tx, transaction)
+		})
+		version = transaction.Version
+		return err
+	})
+	if err != nil {
+		return shareErrorResponse(c, err)
+	}
+
+	ownerIDs, _ := affectedUserIDs(c, c.Params("id"))
+	updateMetrics(c.UserContext(), userID, append(ownerIDs, previous...)...)
+
+	c.Set(fiber.HeaderETag, transactionETag(version))
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Bump the version of a transaction whose shares or other rows changed
+func bumpVersion(db *gorm.DB, transaction *transactionModels.Transaction) error {
+	return db.Model(transaction).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).
+		Update("version", utils.BumpVersion()).Error
+}
+
+// Users a change to the transactions affects besides the acting user: their
+// owners, who may be other members of a shared ledger, and the members
+// holding a share. Deleted transactions count too.
+func affectedUserIDs(c *fiber.Ctx, transactionIDs ...any) (ownerIDs, shareUserIDs []uint) {
+	db := database.DB.WithContext(c.UserContext())
+	if err := db.Unscoped().Model(&transactionModels.Transaction{}).Where("id IN ?", transactionIDs).Distinct().Pluck("user_id", &ownerIDs).Error; err != nil {
+		logs.Ctx(c).Error("Cannot find transaction owners", "error", err)
+	}
+	for _, transactionID := range transactionIDs {
+		userIDs, err := utils.ExpenseShareUserIDs(db, transactionID)
+		if err != nil {
+			logs.Ctx(c).Error("Cannot find share members", "error", err)
+		}
+		shareUserIDs = append(shareUserIDs, userIDs...)
+	}
+	return ownerIDs, shareUserIDs
+}
+
+// Refresh the dashboard metrics of the user and of the other affected users,
+// each user is refreshed once
+func updateMetrics(ctx context.Context, userID uint, otherUserIDs ...uint) {
+	seen := map[uint]bool{userID: true}
+	utils.UpdateDashboardMetrics(ctx, userID)
+	for _, id := range otherUserIDs {
+		if !seen[id] {
+			seen[id] = true
+			utils.UpdateDashboardMetrics(ctx, id)
+		}
+	}
+}
+
+// Map share errors to a response, unexpected errors are logged and return 500
+func shareErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errVersionMismatch):
+		return preconditionFailed()
+	case errors.Is(err, errLedgerForbidden):
+		return err
+	case errors.Is(err, errTransactionNotFound):
+		return apierror.NotFound("Transaction not found")
+	case errors.Is(err, errSharedExpense):
+		return apierror.BadRequest("Only expenses can be shared")
+	case errors.Is(err, errShareNotMember), errors.Is(err, utils.ErrInvalidShares):
+		return apierror.Invalid("members", err.Error())
+	}
+	return apierror.Internal(err, "Cannot share expense")
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Returned when the split lines do not add up to the parent amount.
+var errSplitMismatch = errors.New("split amounts do not add up to the transaction amount")
+
+// Check that split lines add up to the amount, compared in cents
+func splitsMatchAmount(amount float64, splits []transactionModels.SplitRequest) bool {
+	var total int64
+	for _, split := range splits {
+		total += toCents(split.Amount)
+	}
+	return total == toCents(amount)
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// Build split rows from the request, the parent id is set by GORM on create
+func buildSplits(splitReqs []transactionModels.SplitRequest) []transactionModels.TransactionSplit {
+	if len(splitReqs) == 0 {
+		return nil
+	}
+	splits := make([]transactionModels.TransactionSplit, 0, len(splitReqs))
+	for _, split := range splitReqs {
+		splits = append(splits, transactionModels.TransactionSplit{
+			CategoryID: split.CategoryID,
+			Amount:     split.Amount,
+			Memo:       split.Memo,
+		})
+	}
+	return splits
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	suggestionModels "github.com/niko-2609/tracker-expense/models/suggestion"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/suggest"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+)
+
+// Number of suggestions returned
+const suggestionLimit = 3
+
+// Suggests categories for a transaction from a model trained on the user's own history
+func SuggestCategoryHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	suggestReq := new(suggestionModels.SuggestCategoryRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, suggestReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	model, err := suggest.LoadModel(userID)
+	if err != nil {
+		return apierror.Internal(err, "Unable to suggest categories, please try again")
+	}
+
+	// First use, train in the background and let the client retry later
+	if model == nil {
+		suggest.Enqueue(userID)
+		return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+			Status:  "success",
+			Message: "Suggestions are being prepared, try again shortly",
+			Data:    []suggestionModels.CategorySuggestion{},
+		})
+	}
+
+	predictions := model.Predict(suggestReq.Name, suggestReq.Description, suggestReq.Amount, suggestionLimit)
+
+	categoryIDs := make([]uint, 0, len(predictions))
+	for _, prediction := range predictions {
+		categoryIDs = append(categoryIDs, prediction.CategoryID)
+	}
+	var categories []transactionModels.Category
+	if len(categoryIDs) > 0 {
+		if err := database.DB.WithContext(c.UserContext()).Where("id IN ?", categoryIDs).Find(&categories).Error; err != nil {
+			logs.Ctx(c).Error("Cannot fetch suggested categories", "error", err)
+		}
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	suggestions := make([]suggestionModels.CategorySuggestion, 0, len(predictions))
+	for _, prediction := range predictions {
+		suggestions = append(suggestions, suggestionModels.CategorySuggestion{
+			CategoryID:   prediction.CategoryID,
+			CategoryName: names[prediction.CategoryID],
+			Score:        prediction.Score,
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Operation successfull",
+		Data:    suggestions,
+	})
+}
@@ -1,16 +1,25 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/log"
 	"github.com/niko-2609/tracker-expense/database"
+	accountModels "github.com/niko-2609/tracker-expense/models/account"
 	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
 	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	"github.com/niko-2609/tracker-expense/pkg/rules"
+	"github.com/niko-2609/tracker-expense/pkg/suggest"
 	"github.com/niko-2609/tracker-expense/pkg/validation"
 	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Fetch all transactions for a given user from the DB
@@ -18,31 +27,35 @@ func GetTransactionsHandler(c *fiber.Ctx) error {
 
 	userID, err := utils.GetUserId(c)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "User id is required for the transaction",
-			Data:    nil,
-		})
+		return apierror.Unauthorized("User id is required for the transaction")
 	}
 	// Local variable to handle request validation.
 	var transactions []transactionModels.Transaction
 
+	// Optional tag filter, e.g. `?tags=vacation-2026,reimbursable&tag_match=all`
+	var tagNames []string
+	if tags := c.Query("tags"); tags != "" {
+		tagNames = strings.Split(tags, ",")
+	}
+	matchAll := c.Query("tag_match", "any") == "all"
+
+	// Transactions of every ledger the user belongs to, or of one with `?ledger_id=`
+	query := database.DB.WithContext(c.UserContext()).Model(&transactionModels.Transaction{}).Scopes(utils.MemberLedgers(userID))
+	if ledgerID := c.QueryInt("ledger_id"); ledgerID > 0 {
+		if err := utils.CheckLedgerRole(database.DB.WithContext(c.UserContext()), uint(ledgerID), userID, ledgerModels.RoleViewer); err != nil {
+			return err
+		}
+		query = query.Where("transactions.ledger_id = ?", ledgerID)
+	}
+
 	// Fetch transactions from DB
-	result := database.DB.Model(&transactionModels.Transaction{}).Where(&transactionModels.Transaction{
-		UserID: userID,
-	}).Find(&transactions)
+	result := query.Scopes(utils.FilterByTags(tagNames, matchAll)).Preload("Tags").Preload("Splits").Preload("Shares").Find(&transactions)
 	// If error, return no data
 	if result.Error != nil {
-		log.Error(result.Error)
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: fmt.Sprintf("Cannot fetch transactions: %v", result.Error),
-			Data:    nil,
-		})
+		return apierror.Internal(result.Error, "Cannot fetch transactions")
 	}
 
-	// Log success message and return the list of transactions.
-	log.Debug("Retrieved transactions successfully:", transactions)
+	logs.Ctx(c).Debug("Retrieved transactions", "count", len(transactions))
 	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
 		Status:  "success",
 		Message: "Operation successfull",
@@ -55,51 +68,53 @@ func AddTransactionHandler(c *fiber.Ctx) error {
 
 	userID, err := utils.GetUserId(c)
 	if err != nil {
-		log.Error(err.Error())
-		return c.Status(fiber.StatusUnauthorized).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "User id is required for the transaction",
-			Data:    nil,
-		})
+		return apierror.Unauthorized("User id is required for the transaction")
 	}
 	addTransactionReq := new(transactionModels.AddTransactionRequest)
 
 	// Validate incoming request
 	if errs, err := validation.ValidateRequest(c, addTransactionReq); err != nil {
-		log.Error(err.Error())
-		errMsg := validation.CheckErrors(c, errs, err)
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: errMsg,
-			Data:    nil,
-		})
+		return apierror.Validation(errs, err)
 	}
 
-	// Create a new transaction object
-	transaction := &transactionModels.Transaction{
-		UserID:      userID,
-		Name:        addTransactionReq.Name,
-		Frequency:   addTransactionReq.Frequency,
-		Amount:      addTransactionReq.Amount,
-		CategoryID:  addTransactionReq.CategoryID,
-		TxnType:     addTransactionReq.TxnType,
-		TxnDate:     time.Now(),
-		Description: addTransactionReq.Description,
-	}
-
-	// Add transaction to database
-	if err := database.DB.Create(transaction).Error; err != nil {
-		log.Error(err.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Unable to add transaction, please try again",
-			Data:    nil,
-		})
+	// Add transaction, its tags and split lines to database
+	var transaction *transactionModels.Transaction
+	if err := database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		var err error
+		transaction, err = createTransaction(tx, userID, addTransactionReq, c.QueryBool("force"))
+		return err
+	}); err != nil {
+		if errors.Is(err, errSplitMismatch) {
+			return apierror.Invalid("splits", "split amounts must add up to the transaction amount")
+		}
+		if errors.Is(err, errAccountNotFound) {
+			return apierror.Invalid("account_id", "account not found")
+		}
+		if errors.Is(err, errLedgerNotFound) {
+			return apierror.Invalid("ledger_id", "ledger not found")
+		}
+		if errors.Is(err, errLedgerForbidden) {
+			return err
+		}
+		var dupErr *duplicateError
+		if errors.As(err, &dupErr) {
+			return apierror.Conflict("Possible duplicate transaction, retry with ?force=true to add it anyway").WithData(dupErr.Candidates)
+		}
+		if errors.Is(err, errCategoryRequired) {
+			return apierror.Invalid("category_id", "field is required, no rule matched")
+		}
+		return apierror.Internal(err, "Unable to add transaction, please try again")
 	}
 
+	metrics.TransactionsCreated(transaction.TxnType, 1)
+
 	// Update dashboard metrics
-	utils.UpdateDashboardMetrics(userID)
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	// Retrain category suggestions in the background
+	suggest.Enqueue(userID)
 
+	c.Set(fiber.HeaderETag, transactionETag(transaction.Version))
 	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
 		Status:  "success",
 		Message: "Transaction added successfully",
@@ -111,55 +126,80 @@ func AddTransactionHandler(c *fiber.Ctx) error {
 func UpdateTransactionHandler(c *fiber.Ctx) error {
 	userID, err := utils.GetUserId(c)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "User id is required for the transaction",
-			Data:    nil,
-		})
+		return apierror.Unauthorized("User id is required for the transaction")
 	}
 
 	transactionID := c.Params("id")
 	if transactionID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Cannot delete transaction: invalid item request",
-			Data:    nil,
-		})
+		return apierror.BadRequest("Cannot delete transaction: invalid item request")
 	}
 
 	patchTransactionReq := new(transactionModels.UpdateTransactionRequest)
 
 	// Validate incoming request
 	if errs, err := validation.ValidateRequest(c, patchTransactionReq); err != nil {
-		log.Error(err.Error())
-		errMsg := validation.CheckErrors(c, errs, err)
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: errMsg,
-			Data:    nil,
-		})
+		return apierror.Validation(errs, err)
+	}
+
+	// `If-Match` with the ETag the client last saw
+	expected, err := ifMatchVersions(c)
+	if err != nil {
+		return err
 	}
 
 	patchMap := buildPatchMap(patchTransactionReq)
-	if len(patchMap) == 0 {
-		log.Error("No items in PATCH request")
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Atleast 1 items is required for PATCH",
-			Data:    nil,
-		})
+	if len(patchMap) == 0 && patchTransactionReq.Tags == nil && patchTransactionReq.Splits == nil {
+		return apierror.BadRequest("Atleast 1 items is required for PATCH")
 	}
 
-	tx := database.DB.Model(&transactionModels.Transaction{}).Where("id = ? AND user_id = ?", transactionID, userID).Updates(patchMap)
-	if tx.Error != nil {
-		log.Error(tx.Error.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: fmt.Sprintf("Cannot update transaction: %s", tx.Error.Error()),
-			Data:    nil,
-		})
+	var version uint
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		var err error
+		version, err = updateTransaction(tx, userID, transactionID, patchMap, patchTransactionReq, expected)
+		return err
+	})
+	if errors.Is(err, errVersionMismatch) {
+		return preconditionFailed()
+	}
+	if errors.Is(err, errLedgerForbidden) {
+		return err
+	}
+	if errors.Is(err, errTransactionNotFound) {
+		return apierror.NotFound("Cannot update transaction: transaction not found")
+	}
+	if errors.Is(err, errSplitMismatch) {
+		return apierror.Invalid("splits", "split amounts must add up to the transaction amount")
+	}
+	if errors.Is(err, errAccountNotFound) {
+		return apierror.Invalid("account_id", "account not found")
 	}
+	if errors.Is(err, errTransferLeg) {
+		return apierror.BadRequest("Cannot update transaction: use /transfer/:id to change a transfer")
+	}
+	if errors.Is(err, errSharedExpense) {
+		return apierror.BadRequest("Cannot update transaction: remove the shares before changing the type of a shared expense")
+	}
+	if err != nil {
+		return apierror.Internal(err, "Cannot update transaction")
+	}
+
+	// Update dashboard metrics, also of the owner and members holding a share
+	ownerIDs, shareUserIDs := affectedUserIDs(c, transactionID)
+	updateMetrics(c.UserContext(), userID, append(ownerIDs, shareUserIDs...)...)
 
+	// Retrain the owner's category suggestions in the background
+	for _, ownerID := range ownerIDs {
+		suggest.Enqueue(ownerID)
+	}
+
+	if patchTransactionReq.Tags != nil {
+		patchMap["tags"] = utils.NormalizeTagNames(*patchTransactionReq.Tags)
+	}
+	if patchTransactionReq.Splits != nil {
+		patchMap["splits"] = *patchTransactionReq.Splits
+	}
+
+	c.Set(fiber.HeaderETag, transactionETag(version))
 	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
 		Status:  "success",
 		Message: "Transaction updated",
@@ -167,6 +207,224 @@ func UpdateTransactionHandler(c *fiber.Ctx) error {
 	})
 }
 
+// Creates a transaction for the user together with its tags and split lines.
+// Unless `force` is set, a suspected duplicate is rejected with a *duplicateError.
+func createTransaction(db *gorm.DB, userID uint, req *transactionModels.AddTransactionRequest, force bool) (*transactionModels.Transaction, error) {
+	if len(req.Splits) > 0 && !splitsMatchAmount(req.Amount, req.Splits) {
+		return nil, errSplitMismatch
+	}
+
+	// Resolve the account, it must belong to the user
+	var account *accountModels.Account
+	var err error
+	if req.AccountID != 0 {
+		account, err = utils.GetUserAccount(db, userID, req.AccountID)
+	} else {
+		account, err = utils.GetDefaultAccount(db, userID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerID, err := utils.ResolveLedger(db, userID, req.LedgerID, ledgerModels.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Categorization rules fill in the category when none was picked,
+	// normalize the name and add their tags
+	ruleset, err := utils.GetUserRules(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	outcome := rules.Evaluate(ruleset, rules.Candidate{
+		Name:        req.Name,
+		Description: req.Description,
+		Amount:      req.Amount,
+		TxnType:     req.TxnType,
+	})
+
+	name, categoryID := req.Name, req.CategoryID
+	if outcome.Name != nil {
+		name = *outcome.Name
+	}
+	if categoryID == 0 && outcome.CategoryID != nil {
+		categoryID = *outcome.CategoryID
+	}
+	if categoryID == 0 {
+		return nil, errCategoryRequired
+	}
+	tags := append(req.Tags, outcome.Tags...)
+	txnDate := time.Now()
+	if req.TxnDate != "" {
+		if txnDate, err = time.Parse(time.DateOnly, req.TxnDate); err != nil {
+			return nil, err
+		}
+	}
+
+	if !force {
+		candidates, err := utils.FindDuplicateCandidates(db, ledgerID, name, req.Amount, txnDate, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 {
+			return nil, &duplicateError{Candidates: candidates}
+		}
+	}
+
+	// Create a new transaction object
+	transaction := &transactionModels.Transaction{
+		UserID:      userID,
+		LedgerID:    ledgerID,
+		CreatedBy:   userID,
+		AccountID:   account.ID,
+		Name:        name,
+		Frequency:   req.Frequency,
+		Amount:      req.Amount,
+		CategoryID:  categoryID,
+		TxnType:     req.TxnType,
+		TxnDate:     txnDate,
+		Description: req.Description,
+		Splits:      buildSplits(req.Splits),
+		Version:     1,
+	}
+
+	if err := db.Create(transaction).Error; err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		if err := utils.SetTransactionTags(db, transaction, tags); err != nil {
+			return nil, err
+		}
+	}
+	if err := utils.AuditTransactionCreate(db, transaction.ID); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// Applies the patch to a transaction owned by the user and, when set in the request,
+// replaces its tags and split lines. The transaction must be at one of the
+// `expected` versions. Returns the new version.
+func updateTransaction(db *gorm.DB, userID uint, transactionID any, patchMap map[string]any, req *transactionModels.UpdateTransactionRequest, expected expectedVersions) (uint, error) {
+	transaction, err := lockTransaction(db, userID, transactionID, expected)
+	if err != nil {
+		return 0, err
+	}
+	if err := db.Where("transaction_id = ?", transaction.ID).Find(&transaction.Splits).Error; err != nil {
+		return 0, err
+	}
+	if err := db.Where("transaction_id = ?", transaction.ID).Find(&transaction.Shares).Error; err != nil {
+		return 0, err
+	}
+
+	// Both legs of a transfer change together, see UpdateTransferHandler
+	if transaction.TxnType == transactionModels.TxnTypeTransfer {
+		return 0, errTransferLeg
+	}
+
+	// The account must be one of the owner's, who may be another member of the ledger
+	if req.AccountID != nil {
+		if _, err := utils.GetUserAccount(db, transaction.UserID, *req.AccountID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, errAccountNotFound
+			}
+			return 0, err
+		}
+	}
+
+	// A shared expense must stay an expense, see UnshareExpenseHandler
+	if len(transaction.Shares) > 0 && req.TxnType != nil && *req.TxnType != transactionModels.TxnTypeExpense {
+		return 0, errSharedExpense
+	}
+
+	// Split lines must still add up once the patch is applied
+	amount := transaction.Amount
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if req.Splits != nil {
+		if len(*req.Splits) > 0 && !splitsMatchAmount(amount, *req.Splits) {
+			return 0, errSplitMismatch
+		}
+	} else if req.Amount != nil && len(transaction.Splits) > 0 {
+		existing := make([]transactionModels.SplitRequest, 0, len(transaction.Splits))
+		for _, split := range transaction.Splits {
+			existing = append(existing, transactionModels.SplitRequest{Amount: split.Amount})
+		}
+		if !splitsMatchAmount(amount, existing) {
+			return 0, errSplitMismatch
+		}
+	}
+
+	err = utils.AuditTransactionChange(db, transaction.ID, transactionModels.AuditUpdate, func() error {
+		// Tag and split changes bump the version too
+		updates := make(map[string]any, len(patchMap)+1)
+		for k, v := range patchMap {
+			updates[k] = v
+		}
+		updates["version"] = utils.BumpVersion()
+		if err := db.Model(transaction).Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if req.Tags != nil {
+			if err := utils.SetTransactionTags(db, transaction, *req.Tags); err != nil {
+				return err
+			}
+		}
+
+		if req.Splits != nil {
+			if err := db.Where("transaction_id = ?", transaction.ID).Delete(&transactionModels.TransactionSplit{}).Error; err != nil {
+				return err
+			}
+			if splits := buildSplits(*req.Splits); len(splits) > 0 {
+				for i := range splits {
+					splits[i].TransactionID = transaction.ID
+				}
+				if err := db.Create(&splits).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// Shares keep their proportions when the amount changes
+		if req.Amount != nil && len(transaction.Shares) > 0 {
+			return utils.SetExpenseShares(db, transaction.ID, utils.RescaleShares(transaction.Shares, amount))
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return transaction.Version, nil
+}
+
+// Loads a transaction from one of the user's ledgers where the user may edit.
+// With `expected` versions the row is locked for the rest of the DB
+// transaction and must be at one of them.
+func lockTransaction(db *gorm.DB, userID uint, transactionID any, expected expectedVersions) (*transactionModels.Transaction, error) {
+	query := db
+	if len(expected) > 0 {
+		query = db.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+
+	transaction, err := utils.FindLedgerTransaction(query, userID, transactionID, ledgerModels.RoleEditor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !expected.allows(transaction.Version) {
+		return nil, errVersionMismatch
+	}
+	return transaction, nil
+}
+
 func buildPatchMap(patchReq *transactionModels.UpdateTransactionRequest) map[string]any {
 	patchMap := make(map[string]any)
 	if patchReq.Name != nil {
@@ -185,6 +443,9 @@ func buildPatchMap(patchReq *transactionModels.UpdateTransactionRequest) map[str
 	if patchReq.CategoryID != nil {
 		patchMap["category_id"] = patchReq.CategoryID
 	}
+	if patchReq.AccountID != nil {
+		patchMap["account_id"] = patchReq.AccountID
+	}
 	if patchReq.Description != nil {
 		patchMap["description"] = patchReq.Description
 	}
@@ -195,34 +456,121 @@ func buildPatchMap(patchReq *transactionModels.UpdateTransactionRequest) map[str
 func DeleteTransactionHandler(c *fiber.Ctx) error {
 	userID, err := utils.GetUserId(c)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "User id is required for the transaction",
-			Data:    nil,
-		})
+		return apierror.Unauthorized("User id is required for the transaction")
 	}
 
 	transactionID := c.Params("id")
 	if transactionID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(apiModel.Response{
-			Status:  "error",
-			Message: "Cannot delete transaction: invalid item request",
-			Data:    nil,
-		})
+		return apierror.BadRequest("Cannot delete transaction: invalid item request")
 	}
 
-	deleteTX := database.DB.Delete(&transactionModels.Transaction{}, userID, transactionID)
-	if deleteTX.Error != nil {
-		log.Error(deleteTX.Error.Error())
-		return c.Status(fiber.StatusInternalServerError).JSON(apiModel.Response{
-			Status:  "error",
-			Message: fmt.Sprintf("Cannot delete transaction: %s", deleteTX.Error.Error()),
-			Data:    nil,
-		})
+	// `If-Match` with the ETag the client last saw
+	expected, err := ifMatchVersions(c)
+	if err != nil {
+		return err
 	}
 
-	// Update dashboard metrics
-	utils.UpdateDashboardMetrics(userID)
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		return deleteTransaction(tx, userID, transactionID, expected)
+	})
+	if errors.Is(err, errVersionMismatch) {
+		return preconditionFailed()
+	}
+	if errors.Is(err, errLedgerForbidden) {
+		return err
+	}
+	if errors.Is(err, errTransactionNotFound) {
+		return apierror.NotFound("Cannot delete transaction: transaction not found")
+	}
+	if err != nil {
+		return apierror.Internal(err, "Cannot delete transaction")
+	}
+
+	// Update dashboard metrics, also of the owner and members holding a share
+	ownerIDs, shareUserIDs := affectedUserIDs(c, transactionID)
+	updateMetrics(c.UserContext(), userID, append(ownerIDs, shareUserIDs...)...)
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// Restores a deleted transaction of the user
+func RestoreTransactionHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		return restoreTransaction(tx, userID, c.Params("id"))
+	})
+	if errors.Is(err, errLedgerForbidden) {
+		return err
+	}
+	if errors.Is(err, errTransactionNotFound) {
+		return apierror.NotFound("Cannot restore transaction: no deleted transaction found")
+	}
+	if err != nil {
+		return apierror.Internal(err, "Cannot restore transaction")
+	}
+
+	// Update dashboard metrics, also of the owner and members holding a share
+	ownerIDs, shareUserIDs := affectedUserIDs(c, c.Params("id"))
+	updateMetrics(c.UserContext(), userID, append(ownerIDs, shareUserIDs...)...)
 
 	return c.SendStatus(fiber.StatusOK)
 }
+
+// Deletes a transaction from one of the user's ledgers. Deleting either leg of a transfer removes both.
+// The transaction must be at one of the `expected` versions.
+func deleteTransaction(db *gorm.DB, userID uint, transactionID any, expected expectedVersions) error {
+	transaction, err := lockTransaction(db, userID, transactionID, expected)
+	if err != nil {
+		return err
+	}
+
+	legIDs := []uint{transaction.ID}
+	if transaction.TransferID != nil {
+		if err := db.Model(&transactionModels.Transaction{}).Where("transfer_id = ?", *transaction.TransferID).Pluck("id", &legIDs).Error; err != nil {
+			return err
+		}
+	}
+	for _, legID := range legIDs {
+		err := utils.AuditTransactionChange(db, legID, transactionModels.AuditDelete, func() error {
+			return db.Delete(&transactionModels.Transaction{}, legID).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Brings back a deleted transaction from one of the user's ledgers, both legs for a transfer.
+func restoreTransaction(db *gorm.DB, userID uint, transactionID any) error {
+	transaction, err := utils.FindLedgerTransaction(db.Unscoped().Where("transactions.deleted_at IS NOT NULL"), userID, transactionID, ledgerModels.RoleEditor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return errTransactionNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	legIDs := []uint{transaction.ID}
+	if transaction.TransferID != nil {
+		if err := db.Unscoped().Model(&transactionModels.Transaction{}).Where("transfer_id = ? AND deleted_at IS NOT NULL", *transaction.TransferID).Pluck("id", &legIDs).Error; err != nil {
+			return err
+		}
+	}
+	for _, legID := range legIDs {
+		err := utils.AuditTransactionChange(db, legID, transactionModels.AuditRestore, func() error {
+			return db.Unscoped().Model(&transactionModels.Transaction{}).Where("id = ?", legID).Updates(map[string]any{
+				"deleted_at": nil,
+				"version":    utils.BumpVersion(),
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	"github.com/niko-2609/tracker-expense/pkg/validation"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Returned when a transfer leg is edited through the regular update.
+var errTransferLeg = errors.New("transfer legs can only be changed through the transfer endpoint")
+
+// Moves money between two accounts of the user. Creates an `out` leg on the source
+// and an `in` leg on the destination account, linked by a shared transfer id.
+func AddTransferHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	transferReq := new(transactionModels.AddTransferRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, transferReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	var legs []transactionModels.Transaction
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		var err error
+		legs, err = utils.CreateTransfer(tx, userID, transferReq)
+		return err
+	})
+	if err != nil {
+		if apiErr := transferErrorResponse(err); apiErr != nil {
+			return apiErr
+		}
+		return apierror.Internal(err, "Unable to add transfer, please try again")
+	}
+
+	metrics.TransactionsCreated(transactionModels.TxnTypeTransfer, len(legs))
+
+	// Update dashboard metrics
+	utils.UpdateDashboardMetrics(c.UserContext(), userID)
+
+	return c.Status(fiber.StatusAccepted).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Transfer added successfully",
+		Data:    legs,
+	})
+}
+
+// Updates both legs of a transfer, `:id` may be either leg. `If-Match` takes
+// the ETag of that leg, the response carries its new one.
+func UpdateTransferHandler(c *fiber.Ctx) error {
+	userID, err := utils.GetUserId(c)
+	if err != nil {
+		return apierror.Unauthorized("User id is required for the transaction")
+	}
+
+	transactionID := c.Params("id")
+	patchTransferReq := new(transactionModels.UpdateTransferRequest)
+
+	// Validate incoming request
+	if errs, err := validation.ValidateRequest(c, patchTransferReq); err != nil {
+		return apierror.Validation(errs, err)
+	}
+
+	// Fields shared by both legs
+	patchMap := make(map[string]any)
+	if patchTransferReq.Amount != nil {
+		patchMap["amount"] = patchTransferReq.Amount
+	}
+	if patchTransferReq.Name != nil {
+		patchMap["name"] = patchTransferReq.Name
+	}
+	if patchTransferReq.Frequency != nil {
+		patchMap["frequency"] = patchTransferReq.Frequency
+	}
+	if patchTransferReq.Description != nil {
+		patchMap["description"] = patchTransferReq.Description
+	}
+	if len(patchMap) == 0 && patchTransferReq.FromAccountID == nil && patchTransferReq.ToAccountID == nil {
+		return apierror.BadRequest("Atleast 1 items is required for PATCH")
+	}
+
+	// `If-Match` with the ETag the client last saw
+	expected, err := ifMatchVersions(c)
+	if err != nil {
+		return err
+	}
+
+	var version uint
+	err = database.DB.WithContext(utils.AuditContext(c)).Transaction(func(tx *gorm.DB) error {
+		requested, err := lockTransaction(tx, userID, transactionID, expected)
+		if err != nil {
+			return err
+		}
+		// Both legs stay locked until the update is committed
+		legs, err := getTransferLegs(tx.Clauses(clause.Locking{Strength: "UPDATE"}).Session(&gorm.Session{}), userID, requested.ID)
+		if err != nil {
+			return err
+		}
+
+		fromAccountID, toAccountID := legs[transactionModels.TransferOut].AccountID, legs[transactionModels.TransferIn].AccountID
+		if patchTransferReq.FromAccountID != nil {
+			fromAccountID = *patchTransferReq.FromAccountID
+		}
+		if patchTransferReq.ToAccountID != nil {
+			toAccountID = *patchTransferReq.ToAccountID
+		}
+		// Both accounts must be the owner's, who may be another member of the ledger
+		ownerID := legs[transactionModels.TransferOut].UserID
+		if err := utils.CheckTransferAccounts(tx, ownerID, fromAccountID, toAccountID); err != nil {
+			return err
+		}
+
+		for direction, leg := range legs {
+			legPatch := make(map[string]any, len(patchMap)+2)
+			for k, v := range patchMap {
+				legPatch[k] = v
+			}
+			legPatch["version"] = utils.BumpVersion()
+			if direction == transactionModels.TransferOut {
+				legPatch["account_id"] = fromAccountID
+			} else {
+				legPatch["account_id"] = toAccountID
+			}
+			err := utils.AuditTransactionChange(tx, leg.ID, transactionModels.AuditUpdate, func() error {
+				return tx.Model(&leg).Clauses(clause.Returning{Columns: []clause.Column{{Name: "version"}}}).Updates(legPatch).Error
+			})
+			if err != nil {
+				return err
+			}
+			if leg.ID == requested.ID {
+				version = leg.Version
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, errVersionMismatch) {
+		return preconditionFailed()
+	}
+	if err != nil {
+		if apiErr := transferErrorResponse(err); apiErr != nil {
+			return apiErr
+		}
+		return apierror.Internal(err, "Cannot update transfer")
+	}
+
+	// Update dashboard metrics, also of the owner
+	ownerIDs, _ := affectedUserIDs(c, transactionID)
+	updateMetrics(c.UserContext(), userID, ownerIDs...)
+
+	c.Set(fiber.HeaderETag, transactionETag(version))
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Transfer updated",
+		Data:    patchTransferReq,
+	})
+}
+
+// Get both legs of the transfer that the given transaction belongs to, keyed by direction.
+// The user must be allowed to edit the transfer's ledger.
+func getTransferLegs(db *gorm.DB, userID uint, transactionID any) (map[string]transactionModels.Transaction, error) {
+	leg, err := utils.FindLedgerTransaction(db.Where("txn_type = ?", transactionModels.TxnTypeTransfer), userID, transactionID, ledgerModels.RoleEditor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errTransactionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pair []transactionModels.Transaction
+	if err := db.Where("transfer_id = ?", leg.TransferID).Find(&pair).Error; err != nil {
+		return nil, err
+	}
+
+	legs := make(map[string]transactionModels.Transaction, len(pair))
+	for _, p := range pair {
+		legs[p.TransferDirection] = p
+	}
+	if len(legs) != 2 {
+		return nil, errTransactionNotFound
+	}
+	return legs, nil
+}
+
+// Map transfer errors to a client response, nil for unexpected errors
+func transferErrorResponse(err error) *apierror.Error {
+	switch {
+	case errors.Is(err, errTransactionNotFound):
+		return apierror.NotFound("Transfer not found")
+	case errors.Is(err, errAccountNotFound):
+		return apierror.Invalid("account_id", "account not found")
+	case errors.Is(err, errLedgerNotFound):
+		return apierror.Invalid("ledger_id", "ledger not found")
+	case errors.Is(err, errLedgerForbidden):
+		return apierror.From(err)
+	case errors.Is(err, utils.ErrSameAccount):
+		return apierror.Invalid("to_account_id", "transfer accounts must be different")
+	case errors.Is(err, utils.ErrCurrencyMismatch):
+		return apierror.Invalid("to_account_id", "transfer accounts must use the same currency")
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package health serves the liveness and readiness probes. Readiness turns
+// off once the server starts shutting down, so no new traffic is routed here.
+package health
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	"github.com/niko-2609/tracker-expense/migrations"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/suggest"
+)
+
+// Time a readiness check may take
+const checkTimeout = 2 * time.Second
+
+var draining atomic.Bool
+
+// Report not ready from now on, called when the shutdown starts
+func Drain() {
+	draining.Store(true)
+}
+
+// Status of a readiness check
+const (
+	StatusOK          = "ok"
+	StatusUnavailable = "unavailable"
+)
+
+// Result of one readiness check. The probe is public, so the reason a check
+// failed is only logged.
+type Check struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Status string `json:"status"`
+}
+
+// Answers 200 as long as the process serves requests
+func LivenessHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Alive",
+	})
+}
+
+// Answers 200 when the database is reachable, all migrations are applied
+// and the background workers run, 503 with the failed checks otherwise
+func ReadinessHandler(c *fiber.Ctx) error {
+	if draining.Load() {
+		return notReady(c, "Shutting down", nil)
+	}
+
+	ctx, cancel := context.WithTimeout(c.UserContext(), checkTimeout)
+	defer cancel()
+
+	checks := []Check{
+		run("database", checkDatabase(ctx)),
+		run("migrations", checkMigrations(ctx)),
+		run("workers", checkWorkers()),
+	}
+	for _, check := range checks {
+		if !check.OK {
+			return notReady(c, "Not ready", checks)
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(apiModel.Response{
+		Status:  "success",
+		Message: "Ready",
+		Data:    checks,
+	})
+}
+
+// Written here instead of returned, probes failing is no server error to log
+func notReady(c *fiber.Ctx, message string, checks []Check) error {
+	return c.Status(fiber.StatusServiceUnavailable).JSON(apiModel.Response{
+		Status:  "error",
+		Code:    apierror.CodeUnavailable,
+		Message: message,
+		Data:    checks,
+	})
+}
+
+func run(name string, err error) Check {
+	if err != nil {
+		slog.Warn("Readiness check failed", "check", name, "error", err)
+		return Check{Name: name, Status: StatusUnavailable}
+	}
+	return Check{Name: name, OK: true, Status: StatusOK}
+}
+
+func checkDatabase(ctx context.Context) error {
+	if database.DB == nil {
+		return fmt.Errorf("not connected")
+	}
+	sqlDB, err := database.DB.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// The version golang-migrate recorded must be the newest migration, and clean
+func checkMigrations(ctx context.Context) error {
+	if database.DB == nil {
+		return fmt.Errorf("not connected")
+	}
+	latest, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+
+	var applied struct {
+		Version uint64
+		Dirty   bool
+	}
+	if err := database.DB.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&applied).Error; err != nil {
+		return fmt.Errorf("cannot read schema version")
+	}
+	if applied.Dirty {
+		return fmt.Errorf("migration %d failed halfway", applied.Version)
+	}
+	if applied.Version != latest {
+		return fmt.Errorf("at version %d, expected %d", applied.Version, latest)
+	}
+	return nil
+}
+
+func checkWorkers() error {
+	if !suggest.Running() {
+		return fmt.Errorf("suggestion trainer is not running")
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// Package logs sets up structured JSON logging on `log/slog`. Lines logged
+// for a request carry its id, user, method, route and trace id, see `Ctx`.
+package logs
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Shown instead of the value of a sensitive field
+const redacted = "[REDACTED]"
+
+// Keys containing one of these are redacted, at any depth of a logged value
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie", "amount", "balance"}
+
+// Make a JSON logger on stdout the default. The level comes from `LOG_LEVEL`
+// (debug, info, warn or error) and defaults to info.
+func Setup() {
+	level := new(slog.LevelVar)
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			defer slog.Warn("Invalid LOG_LEVEL, using info", "value", value)
+		}
+	}
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})))
+}
+
+// Logger of the request. The user is read when it is called, so lines logged
+// after the token was verified carry the user id.
+func Ctx(c *fiber.Ctx) *slog.Logger {
+	attrs := []any{
+		slog.String("request_id", requestID(c)),
+		slog.String("method", c.Method()),
+		slog.String("route", route(c)),
+	}
+	if userID, ok := userID(c); ok {
+		attrs = append(attrs, slog.Uint64("user_id", uint64(userID)))
+	}
+	if span := trace.SpanContextFromContext(c.UserContext()); span.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+	}
+	return slog.Default().With(attrs...)
+}
+
+func requestID(c *fiber.Ctx) string {
+	if id, ok := c.Locals("requestid").(string); ok {
+		return id
+	}
+	return "-"
+}
+
+// Route pattern such as `/api/transaction/update/:id`, ids stay out of the logs
+func route(c *fiber.Ctx) string {
+	if r := c.Route(); r != nil && r.Path != "" && r.Path != "/" {
+		return r.Path
+	}
+	return c.Path()
+}
+
+// User id from the JWT claims, not set before `Protected` ran
+func userID(c *fiber.Ctx) (uint, bool) {
+	token, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return 0, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return 0, false
+	}
+	id, ok := claims["user_id"].(float64) // JWT is decoded as float64
+	return uint(id), ok
+}
+
+// `ReplaceAttr` hiding sensitive fields. Structs, maps and slices are
+// scrubbed through their JSON form.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 && (attr.Key == slog.TimeKey || attr.Key == slog.MessageKey || attr.Key == slog.LevelKey) {
+		return attr
+	}
+	if isSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	if attr.Value.Kind() != slog.KindAny {
+		return attr
+	}
+
+	value := attr.Value.Any()
+	if err, ok := value.(error); ok {
+		return slog.String(attr.Key, err.Error())
+	}
+	raw, err := json.Marshal(value)
+	if err != nil || len(raw) == 0 || (raw[0] != '{' && raw[0] != '[') {
+		return attr
+	}
+	var decoded any
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return attr
+	}
+	return slog.Any(attr.Key, scrub(decoded))
+}
+
+// Redact sensitive keys of a decoded JSON value
+func scrub(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = scrub(field)
+			}
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = scrub(item)
+		}
+	}
+	return value
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,35 @@
+package logs
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var CustomLogger fiber.Handler = requestLogger
+
+// Logs every request once it was answered, with its status and latency.
+// Server errors are logged at error level, client errors at warn level.
+// Must come before apierror.Middleware, which writes error responses.
+func requestLogger(c *fiber.Ctx) error {
+	start := time.Now()
+	_ = c.Next()
+
+	status := c.Response().StatusCode()
+	level := slog.LevelInfo
+	switch {
+	case status >= fiber.StatusInternalServerError:
+		level = slog.LevelError
+	case status >= fiber.StatusBadRequest:
+		level = slog.LevelWarn
+	}
+
+	Ctx(c).Log(c.UserContext(), level, "request",
+		slog.Int("status", status),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+		slog.String("ip", c.IP()),
+		slog.Int("bytes", len(c.Response().Body())),
+	)
+	return nil
+}
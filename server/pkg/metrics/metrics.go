@@ -0,0 +1,125 @@
+// Package metrics collects Prometheus metrics of the service and serves them
+// in the text format, see `Serve`.
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const namespace = "tracker"
+
+// Registry of all metrics, with the Go runtime and process collectors
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dashboardDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "dashboard_update_duration_seconds",
+		Help:      "Time spent recomputing the dashboard metrics of a user.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	dashboardErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "dashboard_update_errors_total",
+		Help:      "Dashboard metric recomputes that failed.",
+	})
+
+	logins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "auth_logins_total",
+		Help:      "Login attempts by result, success or failure.",
+	}, []string{"result"})
+
+	transactionsCreated = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transactions_created_total",
+		Help:      "Transactions created by type.",
+	}, []string{"txn_type"})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected with 429 by route group.",
+	}, []string{"group"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		dashboardDuration, dashboardErrors,
+		logins, transactionsCreated,
+		rateLimited,
+	)
+}
+
+// Collect the connection pool statistics of the database
+func RegisterDB(db *sql.DB) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, "postgres"))
+}
+
+// Counts requests and their latency by the route pattern, so ids in paths
+// do not create new series. Unmatched paths are counted as `unmatched`.
+// Must come before apierror.Middleware, which writes error responses.
+func Middleware(c *fiber.Ctx) error {
+	start := time.Now()
+	_ = c.Next()
+	status := c.Response().StatusCode()
+
+	route := "unmatched"
+	if r := c.Route(); r != nil && r.Path != "/" {
+		route = r.Path
+	}
+	labels := []string{c.Method(), route, strconv.Itoa(status)}
+	httpRequests.WithLabelValues(labels...).Inc()
+	httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// Record a dashboard recompute, `err` is its result
+func ObserveDashboardUpdate(duration time.Duration, err error) {
+	dashboardDuration.Observe(duration.Seconds())
+	if err != nil {
+		dashboardErrors.Inc()
+	}
+}
+
+// Record a login attempt
+func ObserveLogin(success bool) {
+	result := "failure"
+	if success {
+		result = "success"
+	}
+	logins.WithLabelValues(result).Inc()
+}
+
+// Record created transactions of a type
+func TransactionsCreated(txnType string, count int) {
+	transactionsCreated.WithLabelValues(txnType).Add(float64(count))
+}
+
+// Record a request rejected by the rate limit of a route group
+func RateLimited(group string) {
+	rateLimited.WithLabelValues(group).Inc()
+}
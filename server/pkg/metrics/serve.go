@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const Path = "/metrics"
+
+// Listener of `METRICS_ADDR`, nil when metrics are served by the app
+var server *http.Server
+
+// Serve the metrics at `/metrics`. With `METRICS_ADDR` they get a listener
+// of their own, so the port can stay private. Otherwise they are served by
+// `app` to requests bearing `METRICS_TOKEN`. Without either they are off.
+func Serve(app *fiber.App) {
+	handler := promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+
+	if addr := os.Getenv("METRICS_ADDR"); addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle(Path, handler)
+		server = &http.Server{Addr: addr, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("Metrics listener stopped", "addr", addr, "error", err)
+			}
+		}()
+		return
+	}
+
+	token := os.Getenv("METRICS_TOKEN")
+	if token == "" {
+		slog.Info("Metrics are off, set METRICS_ADDR or METRICS_TOKEN to serve them")
+		return
+	}
+	app.Get(Path, requireToken(token), adaptor.HTTPHandler(handler))
+}
+
+// Stop the metrics listener of `METRICS_ADDR`
+func Shutdown(ctx context.Context) error {
+	if server == nil {
+		return nil
+	}
+	return server.Shutdown(ctx)
+}
+
+// Only lets requests with `Authorization: Bearer <token>` through
+func requireToken(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		bearer, ok := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			return fiber.ErrUnauthorized
+		}
+		return c.Next()
+	}
+}
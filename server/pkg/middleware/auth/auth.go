@@ -1,11 +1,12 @@
 package middleware
 
 import (
+	"errors"
 	"os"
 
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
-	apimodel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
 )
 
 func Protected() fiber.Handler {
@@ -21,18 +22,15 @@ func Protected() fiber.Handler {
 
 // This not just any handler, it takes an `err`
 // along with `fiber.Ctx`. Its an `ErrorHandler`.
+// The reason a token was rejected only goes to the logs.
 func jwtError(c *fiber.Ctx, err error) error {
-	if err.Error() == "missing or malformed JWT" {
-		return c.Status(fiber.StatusBadRequest).JSON(apimodel.Response{
-			Status:  "error",
-			Message: "Unable to verify user",
-			Data:    nil,
-		})
+	if errors.Is(err, jwtware.ErrJWTMissingOrMalformed) {
+		apiErr := apierror.Unauthorized("Unable to verify user")
+		apiErr.Err = err
+		return apiErr
 	}
 
-	return c.Status(fiber.StatusUnauthorized).JSON(apimodel.Response{
-		Status:  "error",
-		Message: err.Error(),
-		Data:    nil,
-	})
+	apiErr := apierror.Unauthorized("Invalid or expired token")
+	apiErr.Err = err
+	return apiErr
 }
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+)
+
+// Route allowed a larger body than the default
+type Route struct {
+	Method string
+	Path   string // Route pattern, e.g. `/api/transaction/:id/attachments`
+	Limit  int
+}
+
+// Reads request bodies up to the limit of their route and rejects larger
+// ones with 413. Every route gets `limit` unless listed in `routes`.
+//
+// The app must be configured with `StreamRequestBody` and a `BodyLimit` of
+// the default limit: larger bodies are then left unread by the server and
+// only read here, so no route but the listed ones ever buffers more. After
+// this middleware the whole body is in memory as usual.
+func New(limit int, routes ...Route) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		allowed := limit
+		for _, route := range routes {
+			if c.Method() == route.Method && matchPath(route.Path, c.Path()) {
+				allowed = route.Limit
+				break
+			}
+		}
+
+		if length := c.Request().Header.ContentLength(); length > allowed {
+			return tooLarge(c, allowed)
+		}
+		if !c.Request().IsBodyStream() {
+			return c.Next()
+		}
+
+		// Chunked bodies have no length up front, read one byte past the limit
+		body, err := io.ReadAll(io.LimitReader(c.Request().BodyStream(), int64(allowed)+1))
+		if err != nil {
+			return apierror.BadRequest("Cannot read the request body")
+		}
+		if len(body) > allowed {
+			return tooLarge(c, allowed)
+		}
+		c.Request().SetBody(body)
+		return c.Next()
+	}
+}
+
+// The rest of the body is left unread, so the connection cannot take
+// another request
+func tooLarge(c *fiber.Ctx, limit int) error {
+	c.Response().Header.SetConnectionClose()
+	return apierror.New(fiber.StatusRequestEntityTooLarge, fmt.Sprintf("Request body is too large, the limit is %d MB", limit>>20))
+}
+
+// Whether the path matches the route pattern, `:name` segments match any
+// single segment
+func matchPath(pattern, path string) bool {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if !strings.HasPrefix(segment, ":") && segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
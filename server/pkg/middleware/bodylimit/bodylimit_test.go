@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+)
+
+func TestBodyLimit(t *testing.T) {
+	const limit, uploadLimit = 1 << 10, 4 << 10
+
+	app := fiber.New(fiber.Config{
+		BodyLimit:                    limit,
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		ErrorHandler:                 apierror.Handler,
+	})
+	app.Use(New(limit, Route{Method: fiber.MethodPost, Path: "/upload/:id", Limit: uploadLimit}))
+	echo := func(c *fiber.Ctx) error {
+		return c.Send(c.Body())
+	}
+	app.Post("/json", echo)
+	app.Post("/upload/:id", echo)
+
+	tests := []struct {
+		name    string
+		path    string
+		size    int
+		chunked bool
+		status  int
+	}{
+		{"within the default", "/json", limit, false, fiber.StatusOK},
+		{"over the default", "/json", limit + 1, false, fiber.StatusRequestEntityTooLarge},
+		{"chunked within the default", "/json", limit, true, fiber.StatusOK},
+		{"chunked over the default", "/json", 2 * limit, true, fiber.StatusRequestEntityTooLarge},
+		{"raised route", "/upload/7", uploadLimit, false, fiber.StatusOK},
+		{"over the raised limit", "/upload/7", uploadLimit + 1, false, fiber.StatusRequestEntityTooLarge},
+		{"default on other paths", "/upload/7/more", 2 * limit, false, fiber.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := bytes.Repeat([]byte("x"), tt.size)
+			req := httptest.NewRequest(fiber.MethodPost, tt.path, bytes.NewReader(body))
+			if tt.chunked {
+				req.ContentLength = -1
+				req.TransferEncoding = []string{"chunked"}
+				req.Body = io.NopCloser(struct{ io.Reader }{bytes.NewReader(body)})
+			}
+
+			resp, err := app.Test(req, -1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.status)
+			}
+			if tt.status == fiber.StatusOK {
+				echoed, _ := io.ReadAll(resp.Body)
+				if len(echoed) != tt.size {
+					t.Errorf("handler read %d bytes, want %d", len(echoed), tt.size)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/database"
+	idempotencyModels "github.com/niko-2609/tracker-expense/models/idempotency"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/utils"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+	defaultTTL   = 24 * time.Hour
+)
+
+// Honours the `Idempotency-Key` header on POST and PATCH. The first request
+// with a key runs as usual and its response is stored for the user; a repeat
+// with the same key and body gets the stored response back instead of running
+// again. Must come after `Protected()` since keys are scoped per user.
+//
+// Keys are kept for `IDEMPOTENCY_TTL` (a Go duration, default 24h).
+func Idempotent() fiber.Handler {
+	ttl := defaultTTL
+	if value := os.Getenv("IDEMPOTENCY_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed <= 0 {
+			slog.Warn("Invalid IDEMPOTENCY_TTL, using the default", "value", value, "default", defaultTTL.String())
+		} else {
+			ttl = parsed
+		}
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderKey)
+		if key == "" || (c.Method() != fiber.MethodPost && c.Method() != fiber.MethodPatch) {
+			return c.Next()
+		}
+		if len(key) > maxKeyLength {
+			return apierror.BadRequest("Invalid request - Idempotency-Key must be at most 255 characters")
+		}
+
+		userID, err := utils.GetUserId(c)
+		if err != nil {
+			return apierror.Unauthorized("User id is required for the request")
+		}
+
+		// The query is part of the request, e.g. `?force=true`
+		hash := sha256.New()
+		hash.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+		hash.Write(c.Body())
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		record, claimed, err := claimKey(c, userID, key, requestHash, ttl)
+		if err != nil {
+			return apierror.Internal(err, "Unable to process request, please try again")
+		}
+
+		if !claimed {
+			if record.RequestHash != requestHash {
+				return apierror.New(fiber.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+			}
+			if record.StatusCode == 0 {
+				return apierror.Conflict("A request with this Idempotency-Key is still in progress")
+			}
+
+			// Replay the stored response
+			c.Set(HeaderReplayed, "true")
+			if record.ContentType != "" {
+				c.Set(fiber.HeaderContentType, record.ContentType)
+			}
+			return c.Status(record.StatusCode).Send(record.ResponseBody)
+		}
+
+		// Render errors of the handler here so client errors are stored and
+		// replayed like any other response
+		if err := c.Next(); err != nil {
+			if err := c.App().Config().ErrorHandler(c, err); err != nil {
+				releaseKey(c, userID, key)
+				return err
+			}
+		}
+
+		// Server errors are not stored so the client can retry with the same key
+		status := c.Response().StatusCode()
+		if status >= fiber.StatusInternalServerError {
+			releaseKey(c, userID, key)
+			return nil
+		}
+
+		err = database.DB.WithContext(c.UserContext()).Model(&idempotencyModels.IdempotencyKey{}).
+			Where("user_id = ? AND key = ?", userID, key).
+			Updates(map[string]any{
+				"status_code":   status,
+				"content_type":  string(c.Response().Header.ContentType()),
+				"response_body": append([]byte(nil), c.Response().Body()...),
+			}).Error
+		if err != nil {
+			logs.Ctx(c).Error("Cannot store idempotent response", "error", err)
+			releaseKey(c, userID, key)
+		}
+		return nil
+	}
+}
+
+// Insert an in-flight record for the key. When the key is already taken the
+// existing record is returned with `claimed` false. The user's expired keys
+// are cleared first so they can be reused.
+func claimKey(c *fiber.Ctx, userID uint, key, requestHash string, ttl time.Duration) (*idempotencyModels.IdempotencyKey, bool, error) {
+	now := time.Now()
+	record := &idempotencyModels.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(ttl),
+	}
+
+	if err := database.DB.WithContext(c.UserContext()).Where("user_id = ? AND expires_at <= ?", userID, now).
+		Delete(&idempotencyModels.IdempotencyKey{}).Error; err != nil {
+		return nil, false, err
+	}
+
+	tx := database.DB.WithContext(c.UserContext()).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if tx.Error != nil {
+		return nil, false, tx.Error
+	}
+	if tx.RowsAffected == 1 {
+		return record, true, nil
+	}
+
+	existing := new(idempotencyModels.IdempotencyKey)
+	if err := database.DB.WithContext(c.UserContext()).Where("user_id = ? AND key = ?", userID, key).First(existing).Error; err != nil {
+		return nil, false, err
+	}
+	return existing, false, nil
+}
+
+// Drop the record so the key can be used again
+func releaseKey(c *fiber.Ctx, userID uint, key string) {
+	if err := database.DB.WithContext(c.UserContext()).Where("user_id = ? AND key = ?", userID, key).
+		Delete(&idempotencyModels.IdempotencyKey{}).Error; err != nil {
+		logs.Ctx(c).Error("Cannot release idempotency key", "error", err)
+	}
+}
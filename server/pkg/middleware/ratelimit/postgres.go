@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Refills the stored bucket and takes a token in one statement, so
+// concurrent requests on any instance never take the same token. The
+// database clock is used so instances with skewed clocks agree.
+const takeQuery = `
+INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at, expires_at)
+VALUES (@key, CAST(@capacity AS float8) - 1, true, now(), now() + make_interval(secs => CAST(@period AS float8)))
+ON CONFLICT (key) DO UPDATE SET
+	tokens = CASE WHEN ` + refill + ` >= 1 THEN ` + refill + ` - 1 ELSE ` + refill + ` END,
+	allowed = ` + refill + ` >= 1,
+	updated_at = now(),
+	expires_at = now() + make_interval(secs => CAST(@period AS float8))
+RETURNING tokens, allowed`
+
+// Tokens of the stored bucket after refilling it
+const refill = `LEAST(CAST(@capacity AS float8), b.tokens + CAST(EXTRACT(EPOCH FROM now() - b.updated_at) AS float8) * CAST(@rate AS float8))`
+
+// Buckets in the `rate_limit_buckets` table, shared by every instance of
+// the service
+type PostgresStore struct {
+	db *gorm.DB
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func NewPostgresStore(db *gorm.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (float64, bool, error) {
+	s.prune(ctx)
+
+	var result struct {
+		Tokens  float64
+		Allowed bool
+	}
+	err := s.db.WithContext(ctx).Raw(takeQuery,
+		sql.Named("key", key),
+		sql.Named("capacity", limit.Requests),
+		sql.Named("rate", limit.rate()),
+		sql.Named("period", limit.Period.Seconds()),
+	).Scan(&result).Error
+	if err != nil {
+		return 0, false, err
+	}
+	return result.Tokens, result.Allowed, nil
+}
+
+// Delete buckets that refilled completely, at most once per prune interval.
+// A bucket is full one period after it was last used.
+func (s *PostgresStore) prune(ctx context.Context) {
+	s.mu.Lock()
+	if time.Since(s.lastPrune) < pruneInterval {
+		s.mu.Unlock()
+		return
+	}
+	s.lastPrune = time.Now()
+	s.mu.Unlock()
+
+	if err := s.db.WithContext(ctx).Exec("DELETE FROM rate_limit_buckets WHERE expires_at <= now()").Error; err != nil {
+		slog.Warn("Cannot prune rate limit buckets", "error", err)
+	}
+}
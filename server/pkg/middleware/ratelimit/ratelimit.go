@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+	"github.com/niko-2609/tracker-expense/pkg/logs"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	"github.com/niko-2609/tracker-expense/utils"
+)
+
+const (
+	HeaderLimit      = "RateLimit-Limit"
+	HeaderRemaining  = "RateLimit-Remaining"
+	HeaderReset      = "RateLimit-Reset"
+	HeaderPolicy     = "RateLimit-Policy"
+	HeaderRetryAfter = fiber.HeaderRetryAfter
+)
+
+// Requests allowed per period. The bucket holds `Requests` tokens and is
+// refilled evenly over `Period`, so bursts up to `Requests` go through.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Tokens added per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Limits of the route groups, overridden by `RATE_LIMIT_<GROUP>`
+var defaultLimits = map[string]Limit{
+	"auth":        {Requests: 10, Period: time.Minute},
+	"transaction": {Requests: 60, Period: time.Minute},
+	"dashboard":   {Requests: 60, Period: time.Minute},
+}
+
+// Limit of groups without one of their own
+var defaultLimit = Limit{Requests: 120, Period: time.Minute}
+
+// Limits requests of the route group per user. Must come after
+// `Protected()` since buckets are keyed by the user id.
+func PerUser(group string) fiber.Handler {
+	return newLimiter(group, func(c *fiber.Ctx) (string, error) {
+		userID, err := utils.GetUserId(c)
+		if err != nil {
+			return "", apierror.Unauthorized("User id is required for the request")
+		}
+		return group + ":user:" + strconv.FormatUint(uint64(userID), 10), nil
+	})
+}
+
+// Limits requests of the route group per client IP, for routes called
+// before the user has a token
+func PerIP(group string) fiber.Handler {
+	return newLimiter(group, func(c *fiber.Ctx) (string, error) {
+		return group + ":ip:" + c.IP(), nil
+	})
+}
+
+// Token bucket limiter of a route group. Every request takes a token from
+// the bucket of its key, without one left it is rejected with 429. The
+// `RateLimit-*` headers tell the client its quota either way.
+//
+// The limit comes from `RATE_LIMIT_<GROUP>`, e.g. `RATE_LIMIT_TRANSACTION=60/1m`
+// for 60 requests a minute, or `off`. Buckets are kept in the store chosen by
+// `RATE_LIMIT_STORE`, see `Default`.
+func newLimiter(group string, key func(c *fiber.Ctx) (string, error)) fiber.Handler {
+	limit, ok := groupLimit(group)
+	if !ok {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+	store := Default()
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, int(math.Ceil(limit.Period.Seconds())))
+
+	return func(c *fiber.Ctx) error {
+		bucket, err := key(c)
+		if err != nil {
+			return err
+		}
+
+		tokens, allowed, err := store.Take(c.UserContext(), bucket, limit)
+		if err != nil {
+			// Better to serve the request than to fail it when the store is down
+			logs.Ctx(c).Error("Cannot check the rate limit", "group", group, "error", err)
+			return c.Next()
+		}
+
+		// Seconds until the bucket is full again
+		reset := math.Ceil((float64(limit.Requests) - tokens) / limit.rate())
+		c.Set(HeaderLimit, strconv.Itoa(limit.Requests))
+		c.Set(HeaderRemaining, strconv.Itoa(int(math.Floor(tokens))))
+		c.Set(HeaderReset, strconv.Itoa(int(reset)))
+		c.Set(HeaderPolicy, policy)
+
+		if !allowed {
+			// Seconds until the next token
+			retryAfter := max(1, int(math.Ceil((1-tokens)/limit.rate())))
+			c.Set(HeaderRetryAfter, strconv.Itoa(retryAfter))
+			metrics.RateLimited(group)
+			return apierror.New(fiber.StatusTooManyRequests, "Too many requests, please try again later")
+		}
+		return c.Next()
+	}
+}
+
+// Limit of the group from `RATE_LIMIT_<GROUP>`, false when it is turned off
+func groupLimit(group string) (Limit, bool) {
+	limit, ok := defaultLimits[group]
+	if !ok {
+		limit = defaultLimit
+	}
+
+	name := "RATE_LIMIT_" + strings.ToUpper(group)
+	value := os.Getenv(name)
+	if value == "" {
+		return limit, true
+	}
+	if value == "off" {
+		return Limit{}, false
+	}
+
+	parsed, err := parseLimit(value)
+	if err != nil {
+		slog.Warn("Invalid "+name+", using the default", "value", value, "default", fmt.Sprintf("%d/%s", limit.Requests, limit.Period))
+		return limit, true
+	}
+	return parsed, true
+}
+
+// `60/1m` is 60 requests per minute
+func parseLimit(value string) (Limit, error) {
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("expected <requests>/<period>")
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("requests must be a positive number")
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("period must be a positive duration")
+	}
+	return Limit{Requests: n, Period: d}, nil
+}
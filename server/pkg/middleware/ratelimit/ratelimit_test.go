@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    Limit
+		wantErr bool
+	}{
+		{"60/1m", Limit{Requests: 60, Period: time.Minute}, false},
+		{"10/30s", Limit{Requests: 10, Period: 30 * time.Second}, false},
+		{"1000/1h30m", Limit{Requests: 1000, Period: 90 * time.Minute}, false},
+		{"", Limit{}, true},
+		{"60", Limit{}, true},
+		{"60/", Limit{}, true},
+		{"/1m", Limit{}, true},
+		{"0/1m", Limit{}, true},
+		{"-5/1m", Limit{}, true},
+		{"many/1m", Limit{}, true},
+		{"60/0s", Limit{}, true},
+		{"60/-1m", Limit{}, true},
+		{"60/minute", Limit{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseLimit(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLimit(%q) error = %v, want error %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseLimit(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
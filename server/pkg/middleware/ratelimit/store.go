@@ -0,0 +1,99 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/niko-2609/tracker-expense/database"
+)
+
+// How often buckets that refilled completely are dropped
+const pruneInterval = time.Minute
+
+// Keeps the token buckets of the limiters
+type Store interface {
+	// Refill the bucket of `key` for the time passed since it was last used
+	// and take a token from it. Returns the tokens left and whether there
+	// was one to take. An unknown key starts with a full bucket.
+	Take(ctx context.Context, key string, limit Limit) (tokens float64, allowed bool, err error)
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore Store
+)
+
+// Store of the limiters, picked by `RATE_LIMIT_STORE`: `memory` (the
+// default) keeps buckets per process, `postgres` shares them between all
+// instances of the service. Needs the database to be connected first.
+func Default() Store {
+	defaultOnce.Do(func() {
+		switch value := os.Getenv("RATE_LIMIT_STORE"); value {
+		case "postgres":
+			defaultStore = NewPostgresStore(database.DB)
+		default:
+			if value != "" && value != "memory" {
+				slog.Warn("Invalid RATE_LIMIT_STORE, using memory", "value", value)
+			}
+			defaultStore = NewMemoryStore()
+		}
+	})
+	return defaultStore
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // Refilled completely by then, unless used again
+}
+
+// Buckets in memory, each instance of the service limits on its own
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (float64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastPrune) >= pruneInterval {
+		s.prune(now)
+	}
+
+	capacity := float64(limit.Requests)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	b.tokens = min(capacity, b.tokens+now.Sub(b.updated).Seconds()*limit.rate())
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((capacity - b.tokens) / limit.rate() * float64(time.Second)))
+	return b.tokens, allowed, nil
+}
+
+// Drop full buckets, they are the same as a missing one
+func (s *MemoryStore) prune(now time.Time) {
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+	s.lastPrune = now
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreTake(t *testing.T) {
+	clock := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return clock }
+	limit := Limit{Requests: 3, Period: 3 * time.Second} // A token per second
+
+	take := func(key string, wantTokens float64, wantAllowed bool) {
+		t.Helper()
+		tokens, allowed, err := store.Take(context.Background(), key, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(tokens-wantTokens) > 1e-9 || allowed != wantAllowed {
+			t.Errorf("Take(%q) = %v, %v, want %v, %v", key, tokens, allowed, wantTokens, wantAllowed)
+		}
+	}
+
+	// A new bucket is full, a burst up to the limit goes through
+	take("a", 2, true)
+	take("a", 1, true)
+	take("a", 0, true)
+	take("a", 0, false)
+
+	// Other keys have buckets of their own
+	take("b", 2, true)
+
+	// Refills evenly, a request needs a whole token
+	clock = clock.Add(500 * time.Millisecond)
+	take("a", 0.5, false)
+	clock = clock.Add(500 * time.Millisecond)
+	take("a", 0, true)
+	clock = clock.Add(1500 * time.Millisecond)
+	take("a", 0.5, true)
+
+	// Never holds more than the limit
+	clock = clock.Add(time.Hour)
+	take("a", 2, true)
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	clock := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return clock }
+	limit := Limit{Requests: 10, Period: time.Hour}
+
+	store.Take(context.Background(), "refilled", Limit{Requests: 10, Period: time.Second})
+	store.Take(context.Background(), "in use", limit)
+
+	clock = clock.Add(pruneInterval)
+	store.Take(context.Background(), "new", limit)
+	if _, ok := store.buckets["refilled"]; ok {
+		t.Error("full bucket was not pruned")
+	}
+	if _, ok := store.buckets["in use"]; !ok {
+		t.Error("bucket that is still refilling was pruned")
+	}
+}
@@ -0,0 +1,382 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	apiModel "github.com/niko-2609/tracker-expense/models/common/api"
+	"github.com/niko-2609/tracker-expense/pkg/apierror"
+)
+
+// Documentation of a route. Paths are written the way Fiber registers
+// them, e.g. `/api/transaction/update/:id`.
+type Operation struct {
+	Method    string
+	Path      string
+	Tag       string
+	Summary   string
+	Public    bool    // Served without a bearer token
+	Unlimited bool    // Not rate limited
+	IfMatch   bool    // Requires `If-Match` with the transaction ETag
+	Query     []Param // Query string parameters
+	Request   any     // JSON body, nil for none
+	Multipart bool    // Body is a multipart form with a `file` field
+	Status    int     // Success status, 200 when zero
+	Response  any     // `data` of the success response, nil for none
+	Binary    bool    // Success response is the raw file
+	Errors    []int   // Error statuses besides the common ones
+	Notes     string  // Longer description
+}
+
+// Query string parameter
+type Param struct {
+	Name        string
+	Type        string // `string`, `integer` or `boolean`
+	Enum        []string
+	Description string
+}
+
+type Document struct {
+	OpenAPI    string                                 `json:"openapi"`
+	Info       Info                                   `json:"info"`
+	Paths      map[string]map[string]*OperationObject `json:"paths"`
+	Components Components                             `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type OperationObject struct {
+	OperationID string               `json:"operationId"`
+	Tags        []string             `json:"tags,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Parameters  []*Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	Security    []map[string][]any   `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+)
+
+// The API document, built once from `Operations`
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = Build(Operations)
+	})
+	return spec
+}
+
+// Build the OpenAPI document for the operations
+func Build(operations []Operation) *Document {
+	builder := &schemaBuilder{schemas: map[string]*Schema{}}
+	builder.structRef(reflect.TypeOf(apiModel.Response{}))
+	codes := builder.schemas["Response"].Properties["code"]
+	for _, code := range apierror.Codes {
+		codes.Enum = append(codes.Enum, code)
+	}
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:   "Tracker Expense API",
+			Version: "1.0.0",
+			Description: "Every JSON response is wrapped in `{status, message, data}`. " +
+				"Error responses add a stable `code` and, for invalid requests, the failed fields in `errors`. " +
+				"Their messages are in the user's preferred locale or the language of `Accept-Language`. " +
+				"POST and PATCH requests on protected routes accept an `Idempotency-Key` header. " +
+				"Requests are rate limited per user, or per client IP under `/api/auth`, with a limit for each route group. " +
+				"Responses carry the quota in `RateLimit-Limit`, `RateLimit-Remaining` and `RateLimit-Reset`; " +
+				"past it they fail with 429 and `Retry-After`.",
+		},
+		Paths: map[string]map[string]*OperationObject{},
+		Components: Components{
+			Schemas: builder.schemas,
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, op := range operations {
+		path := openAPIPath(op.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*OperationObject{}
+		}
+		doc.Paths[path][strings.ToLower(op.Method)] = buildOperation(builder, op)
+	}
+	return doc
+}
+
+func buildOperation(builder *schemaBuilder, op Operation) *OperationObject {
+	item := &OperationObject{
+		OperationID: operationID(op),
+		Tags:        []string{op.Tag},
+		Summary:     op.Summary,
+		Description: op.Notes,
+		Responses:   map[string]*Response{},
+		Security:    []map[string][]any{{"bearerAuth": {}}},
+	}
+	if op.Public {
+		item.Security = []map[string][]any{}
+	}
+
+	// Path parameters, ids are numbers
+	for _, segment := range strings.Split(op.Path, "/") {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			schema := &Schema{Type: "integer", Format: "int64"}
+			if name == "token" {
+				schema = &Schema{Type: "string", Format: "uuid"}
+			}
+			item.Parameters = append(item.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		}
+	}
+	for _, param := range op.Query {
+		schema := &Schema{Type: param.Type}
+		for _, value := range param.Enum {
+			schema.Enum = append(schema.Enum, value)
+		}
+		item.Parameters = append(item.Parameters, &Parameter{Name: param.Name, In: "query", Description: param.Description, Schema: schema})
+	}
+	if !op.Public && (op.Method == fiber.MethodPost || op.Method == fiber.MethodPatch) {
+		item.Parameters = append(item.Parameters, &Parameter{
+			Name:        "Idempotency-Key",
+			In:          "header",
+			Description: "Retries with the same key and body get the stored response back",
+			Schema:      &Schema{Type: "string", MaxLength: intPtr(255)},
+		})
+	}
+	if op.IfMatch {
+		item.Parameters = append(item.Parameters, &Parameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: "ETags of the transaction, comma separated, or `*` for any version. The request fails with 412 when it changed since, 428 without the header",
+			Required:    true,
+			Schema:      &Schema{Type: "string"},
+		})
+	}
+
+	switch {
+	case op.Multipart:
+		item.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"file": {Type: "string", Format: "binary"}},
+				Required:   []string{"file"},
+			}},
+		}}
+	case op.Request != nil:
+		item.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			fiber.MIMEApplicationJSON: {Schema: builder.schemaOf(reflect.TypeOf(op.Request))},
+		}}
+	}
+
+	status := op.Status
+	if status == 0 {
+		status = fiber.StatusOK
+	}
+	success := &Response{Description: http.StatusText(status)}
+	switch {
+	case op.Binary:
+		success.Content = map[string]*MediaType{
+			"application/octet-stream": {Schema: &Schema{Type: "string", Format: "binary"}},
+		}
+	case op.Response != nil:
+		success.Content = map[string]*MediaType{
+			fiber.MIMEApplicationJSON: {Schema: envelope(builder.schemaOf(reflect.TypeOf(op.Response)))},
+		}
+	}
+	item.Responses[strconv.Itoa(status)] = success
+
+	errorStatuses := append([]int{}, op.Errors...)
+	if op.Request != nil || op.Multipart {
+		errorStatuses = append(errorStatuses, fiber.StatusBadRequest)
+	}
+	if !op.Public {
+		errorStatuses = append(errorStatuses, fiber.StatusUnauthorized)
+	}
+	if op.IfMatch {
+		errorStatuses = append(errorStatuses, fiber.StatusPreconditionFailed, fiber.StatusPreconditionRequired)
+	}
+	if !op.Unlimited {
+		errorStatuses = append(errorStatuses, fiber.StatusTooManyRequests)
+	}
+	errorStatuses = append(errorStatuses, fiber.StatusInternalServerError)
+	for _, code := range errorStatuses {
+		item.Responses[strconv.Itoa(code)] = &Response{
+			Description: http.StatusText(code),
+			Content: map[string]*MediaType{
+				fiber.MIMEApplicationJSON: {Schema: &Schema{Ref: "#/components/schemas/Response"}},
+			},
+		}
+	}
+	if !op.Unlimited {
+		item.Responses[strconv.Itoa(fiber.StatusTooManyRequests)].Headers = map[string]*Header{
+			"Retry-After": {Description: "Seconds until the next request is allowed", Schema: &Schema{Type: "integer"}},
+		}
+	}
+	return item
+}
+
+// Success response with `data` of the given schema
+func envelope(data *Schema) *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"status":  {Type: "string", Enum: []any{"success"}},
+			"message": {Type: "string"},
+			"data":    data,
+		},
+		Required: []string{"status", "message", "data"},
+	}
+}
+
+// `/api/transaction/update/:id` becomes `/api/transaction/update/{id}`
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// `POST /api/transaction/update/:id` becomes `postTransactionUpdateId`
+func operationID(op Operation) string {
+	var id strings.Builder
+	id.WriteString(strings.ToLower(op.Method))
+	for _, segment := range strings.FieldsFunc(strings.TrimPrefix(op.Path, "/api"), func(r rune) bool {
+		return r == '/' || r == ':' || r == '-' || r == '.'
+	}) {
+		id.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+	}
+	return id.String()
+}
+
+// Compare the routes registered on the app with `Operations`. Every route
+// under `/api` must be documented and every documented route must exist.
+func CheckRoutes(app *fiber.App) error {
+	documented := make(map[string]bool, len(Operations))
+	for _, op := range Operations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	registered := make(map[string]bool)
+	var missing []string
+	for _, route := range app.GetRoutes(true) {
+		if route.Method == fiber.MethodHead || !strings.HasPrefix(route.Path, "/api") {
+			continue
+		}
+		key := route.Method + " " + route.Path
+		registered[key] = true
+		if !documented[key] {
+			missing = append(missing, key)
+		}
+	}
+
+	var stale []string
+	for key := range documented {
+		if !registered[key] {
+			stale = append(stale, key)
+		}
+	}
+
+	if len(missing) == 0 && len(stale) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, "routes missing from the OpenAPI spec: "+strings.Join(missing, ", "))
+	}
+	if len(stale) > 0 {
+		problems = append(problems, "OpenAPI spec documents unknown routes: "+strings.Join(stale, ", "))
+	}
+	return fmt.Errorf("%s", strings.Join(problems, "; "))
+}
+
+// Serves the OpenAPI document
+func SpecHandler(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(Spec())
+}
+
+// Serves the interactive docs for the OpenAPI document
+func DocsHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Status(fiber.StatusOK).SendString(docsPage)
+}
+
+func intPtr(n int) *int {
+	return &n
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>Tracker Expense API</title>
+	<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+	<div id="docs"></div>
+	<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+	<script>
+		window.ui = SwaggerUIBundle({ url: "/api/openapi.json", dom_id: "#docs", persistAuthorization: true });
+	</script>
+</body>
+</html>
+`
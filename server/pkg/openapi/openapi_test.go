@@ -0,0 +1,37 @@
+package openapi_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/pkg/openapi"
+	"github.com/niko-2609/tracker-expense/pkg/router"
+)
+
+// Every route of the app is documented and every documented route exists
+func TestCheckRoutes(t *testing.T) {
+	app := fiber.New()
+	router.SetupRoutes(app)
+
+	if err := openapi.CheckRoutes(app); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckRoutesReportsDifferences(t *testing.T) {
+	app := fiber.New()
+	app.Get("/api/undocumented", func(c *fiber.Ctx) error { return nil })
+
+	err := openapi.CheckRoutes(app)
+	if err == nil {
+		t.Fatal("CheckRoutes() = nil, want an error")
+	}
+	if !strings.Contains(err.Error(), "routes missing from the OpenAPI spec: GET /api/undocumented") {
+		t.Errorf("undocumented route not reported: %v", err)
+	}
+	op := openapi.Operations[0]
+	if !strings.Contains(err.Error(), op.Method+" "+op.Path) {
+		t.Errorf("documented route %s %s without a handler not reported: %v", op.Method, op.Path, err)
+	}
+}
@@ -0,0 +1,180 @@
+package openapi
+
+import (
+	"github.com/gofiber/fiber/v2"
+	accountModels "github.com/niko-2609/tracker-expense/models/account"
+	attachmentModels "github.com/niko-2609/tracker-expense/models/attachment"
+	authModels "github.com/niko-2609/tracker-expense/models/auth"
+	goalModels "github.com/niko-2609/tracker-expense/models/goal"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	ruleModels "github.com/niko-2609/tracker-expense/models/rule"
+	suggestionModels "github.com/niko-2609/tracker-expense/models/suggestion"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+// Fields that were changed, as sent in the request
+type patch map[string]any
+
+// Every route of `router.SetupRoutes`, keep in sync when adding routes.
+// `CheckRoutes` fails on start when a route is missing here.
+var Operations = []Operation{
+	// Auth
+	{Method: fiber.MethodPost, Path: "/api/auth/login", Tag: "auth", Summary: "Log in and get a JWT", Public: true,
+		Request: authModels.Credentials{}, Response: authModels.AccessPayload{}, Errors: []int{fiber.StatusUnauthorized}},
+	{Method: fiber.MethodPost, Path: "/api/auth/register", Tag: "auth", Summary: "Sign up", Public: true,
+		Request: authModels.Credentials{}, Status: fiber.StatusAccepted, Errors: []int{fiber.StatusConflict}},
+	{Method: fiber.MethodPatch, Path: "/api/auth/preferences", Tag: "auth", Summary: "Change the user's preferences",
+		Request: authModels.PreferencesRequest{}, Response: authModels.AccessPayload{},
+		Notes: "Returns a new token carrying the preferences. Validation messages use the `locale`, without one they follow `Accept-Language`."},
+	{Method: fiber.MethodGet, Path: "/api/test", Tag: "auth", Summary: "Check that the token is accepted", Unlimited: true},
+	{Method: fiber.MethodGet, Path: "/api/openapi.json", Tag: "docs", Summary: "This OpenAPI document", Public: true, Unlimited: true},
+	{Method: fiber.MethodGet, Path: "/api/docs", Tag: "docs", Summary: "Interactive API docs", Public: true, Unlimited: true},
+
+	// Transactions
+	{Method: fiber.MethodGet, Path: "/api/transaction", Tag: "transactions", Summary: "List transactions of the user's ledgers",
+		Query: []Param{
+			{Name: "tags", Type: "string", Description: "Comma separated tag names, matched against the tags of every member of the ledger"},
+			{Name: "tag_match", Type: "string", Enum: []string{"any", "all"}, Description: "Whether any or all tags must match"},
+			{Name: "ledger_id", Type: "integer", Description: "Only transactions of this ledger"},
+		},
+		Response: []transactionModels.Transaction{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+		Notes: "Served with an ETag, `If-None-Match` is answered with 304."},
+	{Method: fiber.MethodPost, Path: "/api/transaction/add", Tag: "transactions", Summary: "Add a transaction",
+		Query:   []Param{{Name: "force", Type: "boolean", Description: "Add it even when it looks like a duplicate"}},
+		Request: transactionModels.AddTransactionRequest{}, Status: fiber.StatusAccepted, Response: transactionModels.AddTransactionRequest{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusConflict},
+		Notes:  "A suspected duplicate is rejected with 409 and the matching transactions as `data`."},
+	{Method: fiber.MethodPatch, Path: "/api/transaction/update/:id", Tag: "transactions", Summary: "Update a transaction", IfMatch: true,
+		Request: transactionModels.UpdateTransactionRequest{}, Response: patch{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/transaction/remove/:id", Tag: "transactions", Summary: "Delete a transaction, both legs for a transfer", IfMatch: true,
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/restore/:id", Tag: "transactions", Summary: "Restore a deleted transaction",
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/api/transaction/:id/history", Tag: "transactions", Summary: "Audit history of a transaction",
+		Response: []transactionModels.TransactionAudit{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodGet, Path: "/api/transaction/:id/attachments", Tag: "attachments", Summary: "List receipts of a transaction",
+		Response: []attachmentModels.Attachment{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/:id/attachments", Tag: "attachments", Summary: "Attach a receipt to a transaction",
+		Multipart: true, Status: fiber.StatusCreated, Response: attachmentModels.Attachment{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusRequestEntityTooLarge, fiber.StatusUnsupportedMediaType},
+		Notes:  "JPEG, PNG, GIF, WebP images and PDF files up to 10 MB."},
+	{Method: fiber.MethodGet, Path: "/api/transaction/:id/shares", Tag: "sharing", Summary: "How an expense is split between ledger members",
+		Response: []transactionModels.ExpenseShare{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/:id/shares", Tag: "sharing", Summary: "Split an expense between ledger members", IfMatch: true,
+		Request: transactionModels.ShareExpenseRequest{}, Response: []transactionModels.ExpenseShare{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/transaction/:id/shares", Tag: "sharing", Summary: "Stop splitting an expense", IfMatch: true,
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/bulk", Tag: "transactions", Summary: "Create, update and delete transactions in one request",
+		Request: transactionModels.BulkTransactionRequest{}, Response: []transactionModels.BulkOperationResult{},
+		Errors: []int{fiber.StatusMultiStatus, fiber.StatusUnprocessableEntity},
+		Notes:  "Updates and deletes need the `version` the transaction must be at, like `If-Match`. Without it the item fails with `VALIDATION_FAILED`, which fails an atomic batch with 422."},
+	{Method: fiber.MethodPost, Path: "/api/transaction/transfer", Tag: "transfers", Summary: "Move money between two accounts",
+		Request: transactionModels.AddTransferRequest{}, Status: fiber.StatusAccepted, Response: []transactionModels.Transaction{},
+		Errors: []int{fiber.StatusForbidden}},
+	{Method: fiber.MethodPatch, Path: "/api/transaction/transfer/:id", Tag: "transfers", Summary: "Update both legs of a transfer", IfMatch: true,
+		Request: transactionModels.UpdateTransferRequest{}, Response: transactionModels.UpdateTransferRequest{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/suggest-category", Tag: "transactions", Summary: "Suggest categories for a new transaction",
+		Request: suggestionModels.SuggestCategoryRequest{}, Response: []suggestionModels.CategorySuggestion{}},
+	{Method: fiber.MethodGet, Path: "/api/transaction/duplicates", Tag: "duplicates", Summary: "List transactions that look like duplicates",
+		Response: []transactionModels.DuplicatePair{}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/duplicates/dismiss", Tag: "duplicates", Summary: "Mark a pair as not being duplicates",
+		Request: transactionModels.DismissDuplicateRequest{}, Response: transactionModels.DuplicateDismissal{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/transaction/duplicates/merge", Tag: "duplicates", Summary: "Merge a duplicate into the transaction that is kept",
+		Request: transactionModels.MergeDuplicateRequest{}, Response: transactionModels.Transaction{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+
+	// Attachments
+	{Method: fiber.MethodGet, Path: "/api/attachments/:id", Tag: "attachments", Summary: "Download a receipt",
+		Query:  []Param{{Name: "thumbnail", Type: "boolean", Description: "Download the JPEG thumbnail instead"}},
+		Binary: true, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/attachments/remove/:id", Tag: "attachments", Summary: "Delete a receipt",
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+
+	// Tags
+	{Method: fiber.MethodGet, Path: "/api/tags", Tag: "tags", Summary: "List tags with their usage",
+		Response: []transactionModels.TagSummary{}},
+	{Method: fiber.MethodPatch, Path: "/api/tags/:id", Tag: "tags", Summary: "Rename a tag",
+		Request: transactionModels.RenameTagRequest{}, Response: transactionModels.Tag{}, Errors: []int{fiber.StatusNotFound, fiber.StatusConflict}},
+	{Method: fiber.MethodPost, Path: "/api/tags/merge", Tag: "tags", Summary: "Merge tags into one",
+		Request: transactionModels.MergeTagsRequest{}, Errors: []int{fiber.StatusNotFound}},
+
+	// Categories
+	{Method: fiber.MethodGet, Path: "/api/categories", Tag: "categories", Summary: "List categories",
+		Response: []transactionModels.CategorySummary{}},
+
+	// Dashboard
+	{Method: fiber.MethodGet, Path: "/api/dashboard", Tag: "dashboard", Summary: "Dashboard metrics of the user",
+		Response: transactionModels.DashboardMetrics{}, Notes: "Served with an ETag, `If-None-Match` is answered with 304."},
+
+	// Accounts
+	{Method: fiber.MethodGet, Path: "/api/accounts", Tag: "accounts", Summary: "List accounts with their balance",
+		Response: []accountModels.AccountBalance{}},
+	{Method: fiber.MethodPost, Path: "/api/accounts/add", Tag: "accounts", Summary: "Add an account",
+		Request: accountModels.AddAccountRequest{}, Status: fiber.StatusAccepted, Response: accountModels.Account{}},
+	{Method: fiber.MethodPatch, Path: "/api/accounts/update/:id", Tag: "accounts", Summary: "Update an account",
+		Request: accountModels.UpdateAccountRequest{}, Response: patch{}, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/accounts/remove/:id", Tag: "accounts", Summary: "Delete an account without transactions",
+		Errors: []int{fiber.StatusNotFound, fiber.StatusConflict}},
+	{Method: fiber.MethodGet, Path: "/api/accounts/:id/history", Tag: "accounts", Summary: "Balance history of an account",
+		Query:    []Param{{Name: "interval", Type: "string", Enum: []string{"day", "month"}}},
+		Response: []accountModels.BalancePoint{}, Errors: []int{fiber.StatusBadRequest, fiber.StatusNotFound}},
+
+	// Goals
+	{Method: fiber.MethodGet, Path: "/api/goals", Tag: "goals", Summary: "List savings goals",
+		Response: []goalModels.SavingsGoal{}},
+	{Method: fiber.MethodPost, Path: "/api/goals/add", Tag: "goals", Summary: "Add a savings goal",
+		Request: goalModels.AddGoalRequest{}, Status: fiber.StatusAccepted, Response: goalModels.SavingsGoal{}},
+	{Method: fiber.MethodPatch, Path: "/api/goals/update/:id", Tag: "goals", Summary: "Update a savings goal",
+		Request: goalModels.UpdateGoalRequest{}, Response: patch{}, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/goals/remove/:id", Tag: "goals", Summary: "Delete a savings goal",
+		Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/goals/:id/contribute", Tag: "goals", Summary: "Contribute to a savings goal",
+		Request: goalModels.ContributeRequest{}, Status: fiber.StatusAccepted, Response: transactionModels.Transaction{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+		Notes:  "Links an income or the `in` leg of a transfer of the goal owner, expenses are rejected. Without a transaction, moves `amount` into the goal's account."},
+	{Method: fiber.MethodGet, Path: "/api/goals/:id/progress", Tag: "goals", Summary: "Progress of a savings goal",
+		Response: goalModels.GoalProgress{}, Errors: []int{fiber.StatusNotFound}},
+
+	// Ledgers
+	{Method: fiber.MethodGet, Path: "/api/ledgers", Tag: "ledgers", Summary: "List the user's ledgers with their role",
+		Response: []ledgerModels.LedgerSummary{}},
+	{Method: fiber.MethodPost, Path: "/api/ledgers/add", Tag: "ledgers", Summary: "Add a shared ledger",
+		Request: ledgerModels.AddLedgerRequest{}, Status: fiber.StatusAccepted, Response: ledgerModels.Ledger{}},
+	{Method: fiber.MethodPatch, Path: "/api/ledgers/update/:id", Tag: "ledgers", Summary: "Rename a ledger",
+		Request: ledgerModels.UpdateLedgerRequest{}, Response: ledgerModels.Ledger{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/ledgers/remove/:id", Tag: "ledgers", Summary: "Delete a shared ledger without transactions",
+		Errors: []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict}},
+	{Method: fiber.MethodPost, Path: "/api/ledgers/invitations/:token/accept", Tag: "ledgers", Summary: "Accept an invitation to a ledger",
+		Response: ledgerModels.LedgerMember{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict}},
+	{Method: fiber.MethodGet, Path: "/api/ledgers/:id/members", Tag: "ledgers", Summary: "List members of a ledger",
+		Response: []ledgerModels.MemberSummary{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/ledgers/:id/members", Tag: "ledgers", Summary: "Invite a user to a ledger by email",
+		Request: ledgerModels.InviteMemberRequest{}, Status: fiber.StatusCreated, Response: ledgerModels.LedgerInvitation{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict}},
+	{Method: fiber.MethodPatch, Path: "/api/ledgers/:id/members/:userId", Tag: "ledgers", Summary: "Change the role of a member",
+		Request: ledgerModels.UpdateMemberRequest{}, Response: ledgerModels.LedgerMember{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/ledgers/:id/members/:userId", Tag: "ledgers", Summary: "Remove a member or leave a ledger",
+		Errors: []int{fiber.StatusBadRequest, fiber.StatusForbidden, fiber.StatusNotFound, fiber.StatusConflict}},
+	{Method: fiber.MethodGet, Path: "/api/ledgers/:id/balances", Tag: "sharing", Summary: "Who owes whom and how to settle up",
+		Response: ledgerModels.LedgerBalances{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound},
+		Notes: "`settle_up` is the fewest payments that settle every balance. With more than 16 open balances it settles them with at most one payment less than there are open balances, which may be more than the fewest."},
+	{Method: fiber.MethodGet, Path: "/api/ledgers/:id/settlements", Tag: "sharing", Summary: "List settlements of a ledger",
+		Response: []ledgerModels.Settlement{}, Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/ledgers/:id/settlements", Tag: "sharing", Summary: "Record a payment between members",
+		Request: ledgerModels.AddSettlementRequest{}, Status: fiber.StatusCreated, Response: ledgerModels.Settlement{},
+		Errors: []int{fiber.StatusForbidden, fiber.StatusNotFound}},
+
+	// Rules
+	{Method: fiber.MethodGet, Path: "/api/rules", Tag: "rules", Summary: "List categorization rules",
+		Response: []ruleModels.CategorizationRule{}},
+	{Method: fiber.MethodPost, Path: "/api/rules/add", Tag: "rules", Summary: "Add a categorization rule",
+		Request: ruleModels.AddRuleRequest{}, Status: fiber.StatusAccepted, Response: ruleModels.CategorizationRule{}},
+	{Method: fiber.MethodPatch, Path: "/api/rules/update/:id", Tag: "rules", Summary: "Update a categorization rule",
+		Request: ruleModels.UpdateRuleRequest{}, Response: patch{}, Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodDelete, Path: "/api/rules/remove/:id", Tag: "rules", Summary: "Delete a categorization rule",
+		Errors: []int{fiber.StatusNotFound}},
+	{Method: fiber.MethodPost, Path: "/api/rules/apply", Tag: "rules", Summary: "Re-run the rules on existing transactions",
+		Request: ruleModels.ApplyRulesRequest{}, Response: []ruleModels.RuleChange{}},
+}
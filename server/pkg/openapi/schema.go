@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// JSON schema as used by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	jsonType      = reflect.TypeOf(datatypes.JSON{})
+	rawType       = reflect.TypeOf(json.RawMessage{})
+)
+
+// Builds schemas from Go types, named structs end up in `schemas` and are
+// referenced from everywhere else
+type schemaBuilder struct {
+	schemas map[string]*Schema
+}
+
+// Schema of a value of type `t`
+func (b *schemaBuilder) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case deletedAtType:
+		return &Schema{Type: "string", Format: "date-time", Nullable: true}
+	case jsonType, rawType:
+		return &Schema{Description: "Free-form JSON"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		minimum := 0.0
+		return &Schema{Type: "integer", Format: "int64", Minimum: &minimum}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: b.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: b.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return b.structRef(t)
+	}
+	// Interfaces such as `any` can hold anything
+	return &Schema{}
+}
+
+// Reference to the component schema of a named struct, built on first use
+func (b *schemaBuilder) structRef(t reflect.Type) *Schema {
+	name := t.Name()
+	if name == "" {
+		return b.structSchema(t)
+	}
+	if _, ok := b.schemas[name]; !ok {
+		// Placeholder first so recursive types terminate
+		b.schemas[name] = &Schema{}
+		*b.schemas[name] = *b.structSchema(t)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// Object schema with a property per JSON field, embedded structs are flattened
+func (b *schemaBuilder) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	b.addFields(schema, t)
+	return schema
+}
+
+func (b *schemaBuilder) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		} else if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			b.addFields(schema, field.Type)
+			continue
+		}
+
+		property := b.schemaOf(field.Type)
+		if applyValidation(property, field.Type, field.Tag.Get("validate")) {
+			schema.Required = append(schema.Required, name)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+// Apply the rules of a `validate` tag to the schema of the field. Rules after
+// `dive` apply to the items of a slice. Returns whether the field is required.
+func applyValidation(schema *Schema, t reflect.Type, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if schema.Items != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+				// Items of referenced structs are validated by their own tags
+				if schema.Items.Ref == "" {
+					applyValidation(schema.Items, t.Elem(), strings.Join(rules[i+1:], ","))
+				}
+			}
+			return required
+		case "email":
+			schema.Format = "email"
+		case "uuid":
+			schema.Format = "uuid"
+		case "datetime":
+			if param == "2006-01-02" {
+				schema.Format = "date"
+			}
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, enumValue(schema, value))
+			}
+		case "len":
+			setBound(schema, t, param, true)
+			setBound(schema, t, param, false)
+		case "min":
+			setBound(schema, t, param, true)
+		case "max":
+			setBound(schema, t, param, false)
+		case "gte":
+			if value, err := strconv.ParseFloat(param, 64); err == nil && isNumber(schema) {
+				schema.Minimum = &value
+			}
+		case "gt":
+			if value, err := strconv.ParseFloat(param, 64); err == nil && isNumber(schema) {
+				schema.Minimum, schema.ExclusiveMinimum = &value, true
+			}
+		case "lte":
+			if value, err := strconv.ParseFloat(param, 64); err == nil && isNumber(schema) {
+				schema.Maximum = &value
+			}
+		case "lt":
+			if value, err := strconv.ParseFloat(param, 64); err == nil && isNumber(schema) {
+				schema.Maximum, schema.ExclusiveMaximum = &value, true
+			}
+		}
+	}
+	return required
+}
+
+// `min`, `max` and `len` limit the length of strings and slices and the value of numbers
+func setBound(schema *Schema, t reflect.Type, param string, lower bool) {
+	switch t.Kind() {
+	case reflect.String:
+		if n, err := strconv.Atoi(param); err == nil {
+			if lower {
+				schema.MinLength = &n
+			} else {
+				schema.MaxLength = &n
+			}
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		if n, err := strconv.Atoi(param); err == nil {
+			if lower {
+				schema.MinItems = &n
+			} else {
+				schema.MaxItems = &n
+			}
+		}
+	default:
+		if value, err := strconv.ParseFloat(param, 64); err == nil && isNumber(schema) {
+			if lower {
+				schema.Minimum = &value
+			} else {
+				schema.Maximum = &value
+			}
+		}
+	}
+}
+
+func isNumber(schema *Schema) bool {
+	return schema.Type == "integer" || schema.Type == "number"
+}
+
+func enumValue(schema *Schema, value string) any {
+	if isNumber(schema) {
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	}
+	return value
+}
@@ -2,17 +2,43 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+	accountHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/accounts"
+	attachmentHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/attachments"
 	handlers "github.com/niko-2609/tracker-expense/pkg/handlers/auth"
+	categoryHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/categories"
+	dashboardHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/dashboard"
+	goalHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/goals"
+	ledgerHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/ledgers"
+	ruleHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/rules"
+	tagHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/tags"
 	transactionHandlers "github.com/niko-2609/tracker-expense/pkg/handlers/transactions"
 	middleware "github.com/niko-2609/tracker-expense/pkg/middleware/auth"
+	idempotency "github.com/niko-2609/tracker-expense/pkg/middleware/idempotency"
+	ratelimit "github.com/niko-2609/tracker-expense/pkg/middleware/ratelimit"
+	"github.com/niko-2609/tracker-expense/pkg/openapi"
 )
 
 func SetupRoutes(app *fiber.App) {
 	api := app.Group("/api")
 
+	// Replays write requests retried with the same Idempotency-Key
+	idempotent := idempotency.Idempotent()
+
+	// ETag of the response body, answers `If-None-Match` with 304
+	revalidate := etag.New()
+
+	// API description, see openapi.Operations
+	api.Get("openapi.json", openapi.SpecHandler)
+	api.Get("docs", openapi.DocsHandler)
+
+	// Token bucket per client IP, the user is not known before logging in
+	authLimit := ratelimit.PerIP("auth")
+
 	auth := api.Group("/auth")
-	auth.Post("/login", handlers.Login)
-	auth.Post("/register", handlers.SignUp)
+	auth.Post("/login", authLimit, handlers.Login)
+	auth.Post("/register", authLimit, handlers.SignUp)
+	auth.Patch("/preferences", authLimit, middleware.Protected(), handlers.UpdatePreferences)
 
 	//test
 	test := api.Group("/test")
@@ -23,9 +49,84 @@ func SetupRoutes(app *fiber.App) {
 		})
 	})
 
+	// Token bucket per user, each group has a limit of its own
 	transaction := api.Group("/transaction")
-	transaction.Get("", middleware.Protected(), transactionHandlers.GetTransactionsHandler)
-	transaction.Post("add", middleware.Protected(), transactionHandlers.AddTransactionHandler)
-	transaction.Patch("update/:id", middleware.Protected(), transactionHandlers.UpdateTransactionHandler)
-	transaction.Delete("remove/:id", middleware.Protected(), transactionHandlers.DeleteTransactionHandler)
+	transactionLimit := ratelimit.PerUser("transaction")
+	transaction.Get("", middleware.Protected(), transactionLimit, revalidate, transactionHandlers.GetTransactionsHandler)
+	transaction.Post("add", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.AddTransactionHandler)
+	transaction.Patch("update/:id", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.UpdateTransactionHandler)
+	transaction.Delete("remove/:id", middleware.Protected(), transactionLimit, transactionHandlers.DeleteTransactionHandler)
+	transaction.Post("restore/:id", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.RestoreTransactionHandler)
+	transaction.Get(":id/history", middleware.Protected(), transactionLimit, transactionHandlers.GetTransactionHistoryHandler)
+	transaction.Get(":id/attachments", middleware.Protected(), transactionLimit, attachmentHandlers.GetAttachmentsHandler)
+	transaction.Post(":id/attachments", middleware.Protected(), transactionLimit, idempotent, attachmentHandlers.UploadAttachmentHandler)
+	transaction.Get(":id/shares", middleware.Protected(), transactionLimit, transactionHandlers.GetSharesHandler)
+	transaction.Post(":id/shares", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.ShareExpenseHandler)
+	transaction.Delete(":id/shares", middleware.Protected(), transactionLimit, transactionHandlers.UnshareExpenseHandler)
+	transaction.Post("bulk", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.BulkTransactionHandler)
+	transaction.Post("transfer", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.AddTransferHandler)
+	transaction.Patch("transfer/:id", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.UpdateTransferHandler)
+	transaction.Post("suggest-category", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.SuggestCategoryHandler)
+	transaction.Get("duplicates", middleware.Protected(), transactionLimit, transactionHandlers.GetDuplicatesHandler)
+	transaction.Post("duplicates/dismiss", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.DismissDuplicateHandler)
+	transaction.Post("duplicates/merge", middleware.Protected(), transactionLimit, idempotent, transactionHandlers.MergeDuplicateHandler)
+
+	attachments := api.Group("/attachments")
+	attachmentsLimit := ratelimit.PerUser("attachments")
+	attachments.Get(":id", middleware.Protected(), attachmentsLimit, attachmentHandlers.DownloadAttachmentHandler)
+	attachments.Delete("remove/:id", middleware.Protected(), attachmentsLimit, attachmentHandlers.DeleteAttachmentHandler)
+
+	tags := api.Group("/tags")
+	tagsLimit := ratelimit.PerUser("tags")
+	tags.Get("", middleware.Protected(), tagsLimit, tagHandlers.GetTagsHandler)
+	tags.Patch(":id", middleware.Protected(), tagsLimit, idempotent, tagHandlers.RenameTagHandler)
+	tags.Post("merge", middleware.Protected(), tagsLimit, idempotent, tagHandlers.MergeTagsHandler)
+
+	categories := api.Group("/categories")
+	categoriesLimit := ratelimit.PerUser("categories")
+	categories.Get("", middleware.Protected(), categoriesLimit, categoryHandlers.GetCategoriesHandler)
+
+	dashboard := api.Group("/dashboard")
+	dashboardLimit := ratelimit.PerUser("dashboard")
+	dashboard.Get("", middleware.Protected(), dashboardLimit, revalidate, dashboardHandlers.GetDashboardHandler)
+
+	accounts := api.Group("/accounts")
+	accountsLimit := ratelimit.PerUser("accounts")
+	accounts.Get("", middleware.Protected(), accountsLimit, accountHandlers.GetAccountsHandler)
+	accounts.Post("add", middleware.Protected(), accountsLimit, idempotent, accountHandlers.AddAccountHandler)
+	accounts.Patch("update/:id", middleware.Protected(), accountsLimit, idempotent, accountHandlers.UpdateAccountHandler)
+	accounts.Delete("remove/:id", middleware.Protected(), accountsLimit, accountHandlers.DeleteAccountHandler)
+	accounts.Get(":id/history", middleware.Protected(), accountsLimit, accountHandlers.GetAccountHistoryHandler)
+
+	goals := api.Group("/goals")
+	goalsLimit := ratelimit.PerUser("goals")
+	goals.Get("", middleware.Protected(), goalsLimit, goalHandlers.GetGoalsHandler)
+	goals.Post("add", middleware.Protected(), goalsLimit, idempotent, goalHandlers.AddGoalHandler)
+	goals.Patch("update/:id", middleware.Protected(), goalsLimit, idempotent, goalHandlers.UpdateGoalHandler)
+	goals.Delete("remove/:id", middleware.Protected(), goalsLimit, goalHandlers.DeleteGoalHandler)
+	goals.Post(":id/contribute", middleware.Protected(), goalsLimit, idempotent, goalHandlers.ContributeGoalHandler)
+	goals.Get(":id/progress", middleware.Protected(), goalsLimit, goalHandlers.GetGoalProgressHandler)
+
+	ledgers := api.Group("/ledgers")
+	ledgersLimit := ratelimit.PerUser("ledgers")
+	ledgers.Get("", middleware.Protected(), ledgersLimit, ledgerHandlers.GetLedgersHandler)
+	ledgers.Post("add", middleware.Protected(), ledgersLimit, idempotent, ledgerHandlers.AddLedgerHandler)
+	ledgers.Patch("update/:id", middleware.Protected(), ledgersLimit, idempotent, ledgerHandlers.UpdateLedgerHandler)
+	ledgers.Delete("remove/:id", middleware.Protected(), ledgersLimit, ledgerHandlers.DeleteLedgerHandler)
+	ledgers.Post("invitations/:token/accept", middleware.Protected(), ledgersLimit, idempotent, ledgerHandlers.AcceptInvitationHandler)
+	ledgers.Get(":id/members", middleware.Protected(), ledgersLimit, ledgerHandlers.GetMembersHandler)
+	ledgers.Post(":id/members", middleware.Protected(), ledgersLimit, idempotent, ledgerHandlers.InviteMemberHandler)
+	ledgers.Patch(":id/members/:userId", middleware.Protected(), ledgersLimit, idempotent, ledgerHandlers.UpdateMemberHandler)
+	ledgers.Delete(":id/members/:userId", middleware.Protected(), ledgersLimit, ledgerHandlers.RemoveMemberHandler)
+	ledgers.Get(":id/balances", middleware.Protected(), ledgersLimit, ledgerHandlers.GetBalancesHandler)
+	ledgers.Get(":id/settlements", middleware.Protected(), ledgersLimit, ledgerHandlers.GetSettlementsHandler)
+	ledgers.Post(":id/settlements", middleware.Protected(), ledgersLimit, idempotent, ledgerHandlers.AddSettlementHandler)
+
+	rules := api.Group("/rules")
+	rulesLimit := ratelimit.PerUser("rules")
+	rules.Get("", middleware.Protected(), rulesLimit, ruleHandlers.GetRulesHandler)
+	rules.Post("add", middleware.Protected(), rulesLimit, idempotent, ruleHandlers.AddRuleHandler)
+	rules.Patch("update/:id", middleware.Protected(), rulesLimit, idempotent, ruleHandlers.UpdateRuleHandler)
+	rules.Delete("remove/:id", middleware.Protected(), rulesLimit, ruleHandlers.DeleteRuleHandler)
+	rules.Post("apply", middleware.Protected(), rulesLimit, idempotent, ruleHandlers.ApplyRulesHandler)
 }
@@ -0,0 +1,56 @@
+package rules
+
+import (
+	"container/list"
+	"regexp"
+	"sync"
+)
+
+// Compiled patterns kept at most, the least recently used go first
+const regexCacheSize = 1024
+
+// Compiled patterns shared by all requests. Bounded, since patterns come
+// from users and every rule edit brings a new one.
+var regexCache = newLRU(regexCacheSize)
+
+type cacheEntry struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // Most recently used first
+	entries map[string]*list.Element
+}
+
+func newLRU(size int) *lru {
+	return &lru{size: size, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (c *lru) get(pattern string) (*regexp.Regexp, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	element, ok := c.entries[pattern]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cacheEntry).re, true
+}
+
+func (c *lru) add(pattern string, re *regexp.Regexp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if element, ok := c.entries[pattern]; ok {
+		c.order.MoveToFront(element)
+		return
+	}
+	c.entries[pattern] = c.order.PushFront(&cacheEntry{pattern: pattern, re: re})
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).pattern)
+	}
+}
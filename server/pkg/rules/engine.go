@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	ruleModels "github.com/niko-2609/tracker-expense/models/rule"
+)
+
+// Fields of a transaction the rules look at
+type Candidate struct {
+	Name        string
+	Description string
+	Amount      float64
+	TxnType     string
+}
+
+// What the matching rules want to change
+type Outcome struct {
+	CategoryID *uint
+	Name       *string
+	Tags       []string
+	RuleIDs    []uint
+}
+
+// Run the rules over a candidate. Rules must be sorted by priority, see utils.GetUserRules.
+// Category and name come from the first matching rule that sets them, tags from all of them.
+func Evaluate(ruleset []ruleModels.CategorizationRule, candidate Candidate) Outcome {
+	var outcome Outcome
+	for _, rule := range ruleset {
+		if !Matches(rule, candidate) {
+			continue
+		}
+
+		changed := false
+		if rule.SetCategoryID != nil && outcome.CategoryID == nil {
+			outcome.CategoryID = rule.SetCategoryID
+			changed = true
+		}
+		if rule.SetName != nil && outcome.Name == nil {
+			outcome.Name = rule.SetName
+			changed = true
+		}
+		if len(rule.SetTags) > 0 {
+			outcome.Tags = append(outcome.Tags, rule.SetTags...)
+			changed = true
+		}
+		if changed {
+			outcome.RuleIDs = append(outcome.RuleIDs, rule.ID)
+		}
+	}
+	return outcome
+}
+
+// Check if a single rule matches the candidate
+func Matches(rule ruleModels.CategorizationRule, candidate Candidate) bool {
+	if !rule.Enabled {
+		return false
+	}
+	if rule.TxnType != nil && *rule.TxnType != candidate.TxnType {
+		return false
+	}
+	if rule.MinAmount != nil && candidate.Amount < *rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount != nil && candidate.Amount > *rule.MaxAmount {
+		return false
+	}
+
+	// An empty pattern only matches on amount and type
+	if rule.Pattern == "" {
+		return true
+	}
+
+	var fields []string
+	switch rule.MatchField {
+	case ruleModels.MatchFieldName:
+		fields = []string{candidate.Name}
+	case ruleModels.MatchFieldDescription:
+		fields = []string{candidate.Description}
+	default:
+		fields = []string{candidate.Name, candidate.Description}
+	}
+
+	for _, field := range fields {
+		if matchPattern(rule.MatchType, rule.Pattern, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check that a pattern can be used with the match type
+func ValidatePattern(matchType, pattern string) error {
+	if matchType != ruleModels.MatchTypeRegex {
+		return nil
+	}
+	if _, err := compile(pattern); err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+	return nil
+}
+
+func matchPattern(matchType, pattern, value string) bool {
+	if matchType == ruleModels.MatchTypeRegex {
+		re, err := compile(pattern)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(value)
+	}
+	return strings.Contains(strings.ToLower(value), strings.ToLower(pattern))
+}
+
+// Regexes match regardless of case, like `contains` patterns
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexCache.get(pattern); ok {
+		return re, nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.add(pattern, re)
+	return re, nil
+}
@@ -0,0 +1,127 @@
+package rules
+
+import (
+	"slices"
+	"testing"
+
+	ruleModels "github.com/niko-2609/tracker-expense/models/rule"
+	"gorm.io/gorm"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestMatches(t *testing.T) {
+	candidate := Candidate{Name: "STARBUCKS #1234", Description: "Card payment", Amount: 4.5, TxnType: "expense"}
+
+	tests := []struct {
+		name string
+		rule ruleModels.CategorizationRule
+		want bool
+	}{
+		{"contains ignores case", ruleModels.CategorizationRule{MatchType: ruleModels.MatchTypeContains, Pattern: "starbucks"}, true},
+		{"contains misses", ruleModels.CategorizationRule{MatchType: ruleModels.MatchTypeContains, Pattern: "costa"}, false},
+		{"regex ignores case", ruleModels.CategorizationRule{MatchType: ruleModels.MatchTypeRegex, Pattern: `^starbucks #\d+$`}, true},
+		{"regex misses", ruleModels.CategorizationRule{MatchType: ruleModels.MatchTypeRegex, Pattern: `^costa`}, false},
+		{"invalid regex", ruleModels.CategorizationRule{MatchType: ruleModels.MatchTypeRegex, Pattern: `starbucks(`}, false},
+		{"name only", ruleModels.CategorizationRule{MatchField: ruleModels.MatchFieldName, MatchType: ruleModels.MatchTypeContains, Pattern: "card"}, false},
+		{"description only", ruleModels.CategorizationRule{MatchField: ruleModels.MatchFieldDescription, MatchType: ruleModels.MatchTypeContains, Pattern: "card"}, true},
+		{"any field", ruleModels.CategorizationRule{MatchField: ruleModels.MatchFieldAny, MatchType: ruleModels.MatchTypeContains, Pattern: "card"}, true},
+		{"empty pattern", ruleModels.CategorizationRule{}, true},
+		{"txn type", ruleModels.CategorizationRule{TxnType: ptr("expense")}, true},
+		{"other txn type", ruleModels.CategorizationRule{TxnType: ptr("income")}, false},
+		{"within bounds", ruleModels.CategorizationRule{MinAmount: ptr(1.0), MaxAmount: ptr(10.0)}, true},
+		{"bounds are inclusive", ruleModels.CategorizationRule{MinAmount: ptr(4.5), MaxAmount: ptr(4.5)}, true},
+		{"below min", ruleModels.CategorizationRule{MinAmount: ptr(5.0)}, false},
+		{"above max", ruleModels.CategorizationRule{MaxAmount: ptr(4.0)}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Enabled = true
+			if got := Matches(tt.rule, candidate); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	disabled := ruleModels.CategorizationRule{Enabled: false, MatchType: ruleModels.MatchTypeContains, Pattern: "starbucks"}
+	if Matches(disabled, candidate) {
+		t.Error("disabled rule matches")
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	rule := func(id uint, pattern string, categoryID *uint, name *string, tags ...string) ruleModels.CategorizationRule {
+		return ruleModels.CategorizationRule{
+			Model:         gorm.Model{ID: id},
+			Enabled:       true,
+			MatchType:     ruleModels.MatchTypeRegex,
+			Pattern:       pattern,
+			SetCategoryID: categoryID,
+			SetName:       name,
+			SetTags:       tags,
+		}
+	}
+	candidate := Candidate{Name: "Uber Eats order", Amount: 25, TxnType: "expense"}
+
+	tests := []struct {
+		name         string
+		ruleset      []ruleModels.CategorizationRule
+		wantCategory *uint
+		wantName     *string
+		wantTags     []string
+		wantRuleIDs  []uint
+	}{
+		{"no rules", nil, nil, nil, nil, nil},
+		{"no match", []ruleModels.CategorizationRule{rule(1, "lyft", ptr[uint](7), nil)}, nil, nil, nil, nil},
+		{
+			"first match sets the category",
+			[]ruleModels.CategorizationRule{rule(1, "uber eats", ptr[uint](7), nil), rule(2, "uber", ptr[uint](8), nil)},
+			ptr[uint](7), nil, nil, []uint{1},
+		},
+		{
+			"later rule fills what the first left open",
+			[]ruleModels.CategorizationRule{rule(1, "uber eats", ptr[uint](7), nil), rule(2, "uber", ptr[uint](8), ptr("Uber"))},
+			ptr[uint](7), ptr("Uber"), nil, []uint{1, 2},
+		},
+		{
+			"tags come from all matches in order",
+			[]ruleModels.CategorizationRule{rule(1, "eats", nil, nil, "food"), rule(2, "^UBER", nil, nil, "delivery"), rule(3, "lyft", nil, nil, "ride")},
+			nil, nil, []string{"food", "delivery"}, []uint{1, 2},
+		},
+		{
+			"matching rule that changes nothing is not listed",
+			[]ruleModels.CategorizationRule{rule(1, "uber", ptr[uint](7), nil), rule(2, "eats", ptr[uint](8), nil)},
+			ptr[uint](7), nil, nil, []uint{1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outcome := Evaluate(tt.ruleset, candidate)
+			if !equalPtr(outcome.CategoryID, tt.wantCategory) {
+				t.Errorf("category = %v, want %v", deref(outcome.CategoryID), deref(tt.wantCategory))
+			}
+			if !equalPtr(outcome.Name, tt.wantName) {
+				t.Errorf("name = %v, want %v", deref(outcome.Name), deref(tt.wantName))
+			}
+			if !slices.Equal(outcome.Tags, tt.wantTags) {
+				t.Errorf("tags = %v, want %v", outcome.Tags, tt.wantTags)
+			}
+			if !slices.Equal(outcome.RuleIDs, tt.wantRuleIDs) {
+				t.Errorf("rule ids = %v, want %v", outcome.RuleIDs, tt.wantRuleIDs)
+			}
+		})
+	}
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func deref[T any](v *T) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
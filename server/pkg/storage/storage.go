@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Returned by Get when no blob is stored under the key
+var ErrNotFound = errors.New("blob not found")
+
+// Blob storage for uploaded files such as receipts. Keys are slash separated paths.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Store used by the handlers, set by Connect
+var Blobs Store
+
+// Connect the store selected by `STORAGE_DRIVER`:
+//   - `local` (default) keeps files under `STORAGE_LOCAL_DIR`, default ./uploads
+//   - `s3` uses an S3 compatible service such as MinIO, configured through
+//     `S3_ENDPOINT`, `S3_ACCESS_KEY`, `S3_SECRET_KEY`, `S3_BUCKET` and `S3_USE_SSL`
+func Connect() error {
+	var err error
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("STORAGE_LOCAL_DIR")
+		if dir == "" {
+			dir = "./uploads"
+		}
+		Blobs, err = NewLocalStore(dir)
+	case "s3":
+		Blobs, err = NewS3Store(S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			UseSSL:    os.Getenv("S3_USE_SSL") == "true",
+		})
+	default:
+		err = fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to connect to blob storage: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+
+	if err := store.Put(context.Background(), "../escape", strings.NewReader("x"), 1, "text/plain"); err == nil {
+		t.Error("Put with a key outside the root succeeded")
+	}
+}
+
+// Runs against a MinIO, e.g. `docker run -p 9000:9000 minio/minio server /data`
+// and `MINIO_ENDPOINT=localhost:9000`. The credentials default to minioadmin.
+func TestS3Store(t *testing.T) {
+	endpoint := os.Getenv("MINIO_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("MINIO_ENDPOINT is not set")
+	}
+	store, err := NewS3Store(S3Config{
+		Endpoint:  endpoint,
+		AccessKey: envOr("MINIO_ACCESS_KEY", "minioadmin"),
+		SecretKey: envOr("MINIO_SECRET_KEY", "minioadmin"),
+		Bucket:    envOr("MINIO_BUCKET", "tracker-expense-test"),
+		UseSSL:    os.Getenv("MINIO_USE_SSL") == "true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+}
+
+// Behaviour every store must have
+func testStore(t *testing.T, store Store) {
+	t.Helper()
+	ctx := context.Background()
+	key := "receipts/" + strings.ReplaceAll(t.Name(), "/", "_") + "/receipt.txt"
+	content := "total 12.50"
+
+	if err := store.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	t.Cleanup(func() {
+		store.Delete(ctx, key)
+	})
+
+	blob, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	read, err := io.ReadAll(blob)
+	blob.Close()
+	if err != nil {
+		t.Fatalf("reading the blob: %v", err)
+	}
+	if string(read) != content {
+		t.Errorf("Get = %q, want %q", read, content)
+	}
+
+	if _, err := store.Get(ctx, key+".missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get of a missing key = %v, want ErrNotFound", err)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+}
+
+func envOr(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
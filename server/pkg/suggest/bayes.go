@@ -0,0 +1,129 @@
+package suggest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Multinomial naive Bayes over the tokens of a transaction's name and
+// description plus a bucket of its amount. Counts are kept as is so the
+// model serializes to JSON and can be stored per user.
+type NaiveBayes struct {
+	Docs        map[uint]int            `json:"docs"`         // Documents per category
+	TokenCounts map[uint]map[string]int `json:"token_counts"` // Token frequency per category
+	TokenTotals map[uint]int            `json:"token_totals"` // Tokens per category
+	Vocabulary  map[string]bool         `json:"vocabulary"`
+	TotalDocs   int                     `json:"total_docs"`
+}
+
+// Single training example
+type Document struct {
+	CategoryID  uint
+	Name        string
+	Description string
+	Amount      float64
+}
+
+// Category with its probability
+type Prediction struct {
+	CategoryID uint
+	Score      float64
+}
+
+func NewNaiveBayes() *NaiveBayes {
+	return &NaiveBayes{
+		Docs:        map[uint]int{},
+		TokenCounts: map[uint]map[string]int{},
+		TokenTotals: map[uint]int{},
+		Vocabulary:  map[string]bool{},
+	}
+}
+
+// Train the model on the documents
+func (nb *NaiveBayes) Train(docs []Document) {
+	for _, doc := range docs {
+		if doc.CategoryID == 0 {
+			continue
+		}
+		nb.Docs[doc.CategoryID]++
+		nb.TotalDocs++
+		if nb.TokenCounts[doc.CategoryID] == nil {
+			nb.TokenCounts[doc.CategoryID] = map[string]int{}
+		}
+		for _, token := range Tokenize(doc.Name, doc.Description, doc.Amount) {
+			nb.TokenCounts[doc.CategoryID][token]++
+			nb.TokenTotals[doc.CategoryID]++
+			nb.Vocabulary[token] = true
+		}
+	}
+}
+
+// Rank categories for a transaction, best first, at most `limit` of them.
+// Scores are probabilities and add up to 1 across all categories.
+func (nb *NaiveBayes) Predict(name, description string, amount float64, limit int) []Prediction {
+	if nb.TotalDocs == 0 {
+		return []Prediction{}
+	}
+
+	tokens := Tokenize(name, description, amount)
+	vocabulary := float64(len(nb.Vocabulary))
+
+	logScores := make(map[uint]float64, len(nb.Docs))
+	maxScore := math.Inf(-1)
+	for categoryID, docs := range nb.Docs {
+		// Log prior plus Laplace smoothed log likelihood of every token
+		score := math.Log(float64(docs) / float64(nb.TotalDocs))
+		denominator := float64(nb.TokenTotals[categoryID]) + vocabulary
+		for _, token := range tokens {
+			score += math.Log((float64(nb.TokenCounts[categoryID][token]) + 1) / denominator)
+		}
+		logScores[categoryID] = score
+		maxScore = math.Max(maxScore, score)
+	}
+
+	// Softmax back to probabilities
+	var total float64
+	predictions := make([]Prediction, 0, len(logScores))
+	for categoryID, score := range logScores {
+		p := math.Exp(score - maxScore)
+		total += p
+		predictions = append(predictions, Prediction{CategoryID: categoryID, Score: p})
+	}
+	for i := range predictions {
+		predictions[i].Score = math.Round(predictions[i].Score/total*10000) / 10000
+	}
+
+	sort.Slice(predictions, func(i, j int) bool {
+		if predictions[i].Score == predictions[j].Score {
+			return predictions[i].CategoryID < predictions[j].CategoryID
+		}
+		return predictions[i].Score > predictions[j].Score
+	})
+	if limit > 0 && len(predictions) > limit {
+		predictions = predictions[:limit]
+	}
+	return predictions
+}
+
+// Split name and description into lower case word tokens and add
+// a token for the order of magnitude of the amount.
+func Tokenize(name, description string, amount float64) []string {
+	words := strings.FieldsFunc(strings.ToLower(name+" "+description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words)+1)
+	for _, word := range words {
+		if len([]rune(word)) < 2 {
+			continue
+		}
+		tokens = append(tokens, word)
+	}
+	if amount > 0 {
+		tokens = append(tokens, fmt.Sprintf("__amount_%d", int(math.Floor(math.Log2(amount)))))
+	}
+	return tokens
+}
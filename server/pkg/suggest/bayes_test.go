@@ -0,0 +1,104 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name        string
+		txnName     string
+		description string
+		amount      float64
+		want        []string
+	}{
+		{"lower case words", "Coffee Shop", "", 0, []string{"coffee", "shop"}},
+		{"punctuation splits", "uber*trip", "ride-share, NYC", 0, []string{"uber", "trip", "ride", "share", "nyc"}},
+		{"single characters dropped", "a b cd", "x", 0, []string{"cd"}},
+		{"digits kept", "Rent 2024", "", 0, []string{"rent", "2024"}},
+		{"unicode letters", "Café Zürich", "", 0, []string{"café", "zürich"}},
+		{"amount bucket", "Rent", "", 1200, []string{"rent", "__amount_10"}},
+		{"small amount bucket", "", "", 0.5, []string{"__amount_-1"}},
+		{"no amount bucket", "Refund", "", -20, []string{"refund"}},
+		{"empty", "", "", 0, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Tokenize(tt.txnName, tt.description, tt.amount)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Tokenize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPredict(t *testing.T) {
+	const (
+		food uint = iota + 1
+		transport
+		rent
+	)
+	trained := func() *NaiveBayes {
+		nb := NewNaiveBayes()
+		nb.Train([]Document{
+			{CategoryID: food, Name: "Coffee shop"},
+			{CategoryID: food, Name: "Coffee beans"},
+			{CategoryID: food, Name: "Grocery store"},
+			{CategoryID: food, Name: "Bakery"},
+			{CategoryID: transport, Name: "Uber trip"},
+			{CategoryID: transport, Name: "Train ticket"},
+			{CategoryID: rent, Name: "Monthly rent", Amount: 1200},
+			{CategoryID: 0, Name: "Uncategorized coffee"}, // Ignored
+		})
+		return nb
+	}
+
+	tests := []struct {
+		name        string
+		model       *NaiveBayes
+		txnName     string
+		description string
+		amount      float64
+		limit       int
+		want        []uint // Category ids in order
+		wantScores  []float64
+	}{
+		{"untrained", NewNaiveBayes(), "Coffee", "", 0, 0, []uint{}, nil},
+		// Without tokens only the share of documents per category counts
+		{"prior only", trained(), "", "", 0, 0, []uint{food, transport, rent}, []float64{4.0 / 7, 2.0 / 7, 1.0 / 7}},
+		{"unseen tokens", trained(), "Zebra", "xylophone", 0, 0, []uint{food, transport, rent}, nil},
+		{"seen token", trained(), "Coffee", "", 0, 0, []uint{food, transport, rent}, nil},
+		{"token of a small category", trained(), "Uber ride", "", 0, 0, []uint{transport, food, rent}, nil},
+		{"name and amount bucket", trained(), "Rent", "", 1500, 0, []uint{rent, food, transport}, nil},
+		{"limit", trained(), "Train", "", 0, 1, []uint{transport}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			predictions := tt.model.Predict(tt.txnName, tt.description, tt.amount, tt.limit)
+
+			got := make([]uint, len(predictions))
+			for i, prediction := range predictions {
+				got[i] = prediction.CategoryID
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("Predict() ranked %v, want %v", got, tt.want)
+			}
+			for i, want := range tt.wantScores {
+				if diff := predictions[i].Score - want; diff > 0.0001 || diff < -0.0001 {
+					t.Errorf("score of %d = %v, want %v", predictions[i].CategoryID, predictions[i].Score, want)
+				}
+			}
+
+			if tt.limit == 0 && len(predictions) > 0 {
+				var total float64
+				for _, prediction := range predictions {
+					total += prediction.Score
+				}
+				if total < 0.999 || total > 1.001 {
+					t.Errorf("scores add up to %v, want 1", total)
+				}
+			}
+		})
+	}
+}
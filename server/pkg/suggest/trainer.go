@@ -0,0 +1,157 @@
+package suggest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/niko-2609/tracker-expense/database"
+	suggestionModels "github.com/niko-2609/tracker-expense/models/suggestion"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Background trainer, users are queued once even if enqueued several times
+var (
+	queue   chan uint
+	pending sync.Map
+	workers sync.WaitGroup
+	mu      sync.RWMutex
+	stopped bool
+)
+
+// Start the background workers that retrain user models
+func StartTrainer(workerCount int) {
+	queue = make(chan uint, 1024)
+	for i := 0; i < workerCount; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for userID := range queue {
+				pending.Delete(userID)
+				if err := Train(userID); err != nil {
+					slog.Error("Training category model failed", "user_id", userID, "error", err)
+				}
+			}
+		}()
+	}
+}
+
+// Stop accepting work and wait for queued trainings until the context expires
+func StopTrainer(ctx context.Context) error {
+	mu.Lock()
+	if queue == nil || stopped {
+		mu.Unlock()
+		return nil
+	}
+	stopped = true
+	close(queue)
+	mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Whether the workers were started and not stopped yet
+func Running() bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return queue != nil && !stopped
+}
+
+// Queue a retrain of the user's model. Never blocks, drops the request when the queue is full.
+func Enqueue(userID uint) {
+	mu.RLock()
+	defer mu.RUnlock()
+	if queue == nil || stopped {
+		return
+	}
+	if _, queued := pending.LoadOrStore(userID, true); queued {
+		return
+	}
+	select {
+	case queue <- userID:
+	default:
+		pending.Delete(userID)
+		slog.Warn("Training queue full, skipping category model", "user_id", userID)
+	}
+}
+
+// Train the user's model on their categorized transactions and store it
+func Train(userID uint) error {
+	var transactions []transactionModels.Transaction
+	if err := database.DB.Where("user_id = ? AND txn_type <> ?", userID, transactionModels.TxnTypeTransfer).
+		Preload("Splits").
+		Find(&transactions).Error; err != nil {
+		return err
+	}
+
+	// Split transactions teach every split category
+	docs := make([]Document, 0, len(transactions))
+	for _, transaction := range transactions {
+		if len(transaction.Splits) == 0 {
+			docs = append(docs, Document{
+				CategoryID:  transaction.CategoryID,
+				Name:        transaction.Name,
+				Description: transaction.Description,
+				Amount:      transaction.Amount,
+			})
+			continue
+		}
+		for _, split := range transaction.Splits {
+			docs = append(docs, Document{
+				CategoryID:  split.CategoryID,
+				Name:        transaction.Name,
+				Description: split.Memo,
+				Amount:      split.Amount,
+			})
+		}
+	}
+
+	nb := NewNaiveBayes()
+	nb.Train(docs)
+
+	encoded, err := json.Marshal(nb)
+	if err != nil {
+		return err
+	}
+
+	model := suggestionModels.CategoryModel{
+		UserID:           userID,
+		Model:            encoded,
+		TransactionCount: len(transactions),
+		TrainedAt:        time.Now(),
+	}
+	return database.DB.Clauses(clause.OnConflict{UpdateAll: true}).Create(&model).Error
+}
+
+// Load the stored model of the user, nil when it has not been trained yet
+func LoadModel(userID uint) (*NaiveBayes, error) {
+	var model suggestionModels.CategoryModel
+	if err := database.DB.Where("user_id = ?", userID).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	nb := NewNaiveBayes()
+	if err := json.Unmarshal(model.Model, nb); err != nil {
+		return nil, err
+	}
+	return nb, nil
+}
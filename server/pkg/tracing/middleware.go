@@ -0,0 +1,50 @@
+package tracing
+
+import (
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Starts a server span for every request, continuing the trace of the
+// caller's `traceparent`. Handlers reach it through `c.UserContext()`.
+// Must come before apierror.Middleware, which writes error responses.
+func Middleware(c *fiber.Ctx) error {
+	carrier := propagation.MapCarrier{}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		carrier[strings.ToLower(string(key))] = string(value)
+	})
+	ctx := otel.GetTextMapPropagator().Extract(c.UserContext(), carrier)
+
+	ctx, span := Tracer.Start(ctx, c.Method()+" "+c.Path(),
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(c.Method()),
+			semconv.URLPath(c.Path()),
+			semconv.ClientAddress(c.IP()),
+			semconv.UserAgentOriginal(c.Get(fiber.HeaderUserAgent)),
+		),
+	)
+	defer span.End()
+	c.SetUserContext(ctx)
+
+	// Errors were already written and recorded by apierror.Middleware
+	_ = c.Next()
+
+	// The route is only known once the request was matched
+	if r := c.Route(); r != nil && r.Path != "/" {
+		span.SetName(c.Method() + " " + r.Path)
+		span.SetAttributes(semconv.HTTPRoute(r.Path))
+	}
+	status := c.Response().StatusCode()
+	span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+	if status >= fiber.StatusInternalServerError {
+		span.SetStatus(codes.Error, "")
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+// Package tracing sets up OpenTelemetry tracing. Requests, GORM queries and
+// dashboard recomputes get spans, trace context is propagated in the W3C
+// `traceparent` and `baggage` headers.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const serviceName = "tracker-expense"
+
+// Tracer of the service, spans are dropped until `Setup` ran
+var Tracer trace.Tracer = otel.Tracer(serviceName)
+
+// Configure the exporter from `OTEL_TRACES_EXPORTER`:
+//   - `otlp` sends spans over OTLP/HTTP, configured by the standard
+//     `OTEL_EXPORTER_OTLP_*` variables. The default when an endpoint is set.
+//   - `stdout` prints them, for local runs.
+//   - `file` writes them as JSON lines to `OTEL_TRACES_FILE`.
+//   - `none` turns tracing off. The default without an endpoint.
+//
+// The returned function flushes pending spans and stops the exporter.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporter, closer, err := newExporter(ctx)
+	if err != nil || exporter == nil {
+		return func(context.Context) error { return nil }, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			closer.Close()
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context) (sdktrace.SpanExporter, io.Closer, error) {
+	kind := os.Getenv("OTEL_TRACES_EXPORTER")
+	if kind == "" {
+		kind = "none"
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			kind = "otlp"
+		}
+	}
+
+	switch kind {
+	case "otlp":
+		exporter, err := otlptracehttp.New(ctx)
+		return exporter, nil, err
+	case "stdout", "console":
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		return exporter, nil, err
+	case "file":
+		path := os.Getenv("OTEL_TRACES_FILE")
+		if path == "" {
+			return nil, nil, fmt.Errorf("OTEL_TRACES_FILE is required for the file exporter")
+		}
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		return exporter, file, err
+	case "none":
+		return nil, nil, nil
+	}
+	return nil, nil, fmt.Errorf("unknown OTEL_TRACES_EXPORTER %q", kind)
+}
@@ -0,0 +1,179 @@
+package validation
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/de"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/es"
+	"github.com/go-playground/locales/fr"
+	"github.com/go-playground/locales/it"
+	"github.com/go-playground/locales/ja"
+	"github.com/go-playground/locales/nl"
+	"github.com/go-playground/locales/pt"
+	"github.com/go-playground/locales/pt_BR"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	de_translations "github.com/go-playground/validator/v10/translations/de"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	es_translations "github.com/go-playground/validator/v10/translations/es"
+	fr_translations "github.com/go-playground/validator/v10/translations/fr"
+	it_translations "github.com/go-playground/validator/v10/translations/it"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+	nl_translations "github.com/go-playground/validator/v10/translations/nl"
+	pt_translations "github.com/go-playground/validator/v10/translations/pt"
+	pt_BR_translations "github.com/go-playground/validator/v10/translations/pt_BR"
+	zh_translations "github.com/go-playground/validator/v10/translations/zh"
+	"github.com/gofiber/fiber/v2"
+	"github.com/niko-2609/tracker-expense/utils"
+)
+
+// Messages are in English when no supported locale was asked for
+const DefaultLocale = "en"
+
+// Locales with validation messages and how their translations are registered
+var translations = []struct {
+	locale   locales.Translator
+	register func(*validator.Validate, ut.Translator) error
+}{
+	{en.New(), en_translations.RegisterDefaultTranslations},
+	{de.New(), de_translations.RegisterDefaultTranslations},
+	{es.New(), es_translations.RegisterDefaultTranslations},
+	{fr.New(), fr_translations.RegisterDefaultTranslations},
+	{it.New(), it_translations.RegisterDefaultTranslations},
+	{ja.New(), ja_translations.RegisterDefaultTranslations},
+	{nl.New(), nl_translations.RegisterDefaultTranslations},
+	{pt.New(), pt_translations.RegisterDefaultTranslations},
+	{pt_BR.New(), pt_BR_translations.RegisterDefaultTranslations},
+	{zh.New(), zh_translations.RegisterDefaultTranslations},
+}
+
+var universal *ut.UniversalTranslator
+
+// Register the translations of every locale on `Validate`
+func registerTranslations() {
+	universal = ut.New(translations[0].locale)
+	for _, translation := range translations {
+		if err := universal.AddTranslator(translation.locale, true); err != nil {
+			panic(err)
+		}
+		trans, _ := universal.GetTranslator(translation.locale.Locale())
+		if err := translation.register(Validate, trans); err != nil {
+			panic(err)
+		}
+
+		// Allowed values and other fields are listed by their JSON names
+		for tag, param := range map[string]func(string) string{
+			"oneof":   listValues,
+			"nefield": jsonFieldName,
+			"eqfield": jsonFieldName,
+		} {
+			if err := Validate.RegisterTranslation(tag, trans, keepTranslation, translateParam(param)); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// Translator for the request, the user's preferred locale comes first and
+// then the languages of `Accept-Language` by their weight
+func translator(c *fiber.Ctx) ut.Translator {
+	candidates := acceptLanguages(c.Get(fiber.HeaderAcceptLanguage))
+	if locale := utils.GetUserLocale(c); locale != "" {
+		candidates = append([]string{locale}, candidates...)
+	}
+	trans, _ := universal.FindTranslator(candidates...)
+	return trans
+}
+
+// Message of a failed rule in the locale of `trans`. Rules without a
+// translation in that locale fall back to English.
+func translate(fieldErr validator.FieldError, trans ut.Translator) string {
+	message := fieldErr.Translate(trans)
+	if message == fieldErr.Error() && trans.Locale() != DefaultLocale {
+		fallback, _ := universal.GetTranslator(DefaultLocale)
+		message = fieldErr.Translate(fallback)
+	}
+	if message == fieldErr.Error() {
+		return "Invalid value"
+	}
+	return message
+}
+
+// Locales of an `Accept-Language` header ordered by weight. Region tags
+// such as `pt-BR` are followed by their base language.
+func acceptLanguages(header string) []string {
+	type language struct {
+		tag    string
+		weight float64
+	}
+	var languages []language
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = parsed
+			}
+		}
+		if weight > 0 {
+			languages = append(languages, language{tag: tag, weight: weight})
+		}
+	}
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].weight > languages[j].weight
+	})
+
+	candidates := make([]string, 0, len(languages)*2)
+	for _, lang := range languages {
+		tag := strings.ReplaceAll(lang.tag, "-", "_")
+		candidates = append(candidates, tag)
+		if base, _, ok := strings.Cut(tag, "_"); ok {
+			candidates = append(candidates, base)
+		}
+	}
+	return candidates
+}
+
+// Keeps the text registered by the locale's default translations
+func keepTranslation(ut.Translator) error {
+	return nil
+}
+
+// Translation with the rule's parameter rewritten by `param`
+func translateParam(param func(string) string) validator.TranslationFunc {
+	return func(trans ut.Translator, fieldErr validator.FieldError) string {
+		message, err := trans.T(fieldErr.Tag(), fieldErr.Field(), param(fieldErr.Param()))
+		if err != nil {
+			return fieldErr.Error()
+		}
+		return message
+	}
+}
+
+// `oneof` values separated by commas, e.g. `income, expense`
+func listValues(param string) string {
+	return strings.Join(strings.Fields(param), ", ")
+}
+
+// JSON name of a Go struct field, e.g. `FromAccountID` is `from_account_id`
+func jsonFieldName(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 &&
+			(unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
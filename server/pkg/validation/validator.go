@@ -4,16 +4,20 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
 
 type ValidationError struct {
-	Field   string
-	Message string
+	Field   string // JSON path of the field, e.g. `operations[0].amount`
+	Message string // In the locale of the request
 }
 
+// Decodes the JSON body into `input` and validates it. Failed rules are
+// returned with messages in the user's preferred locale or the one of
+// `Accept-Language`.
 func ValidateRequest(c *fiber.Ctx, input any) ([]ValidationError, error) {
 	// Check raw incoming request
 	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
@@ -25,11 +29,12 @@ func ValidateRequest(c *fiber.Ctx, input any) ([]ValidationError, error) {
 	// Validate request using validator
 	if err := Validate.Struct(input); err != nil {
 		if errs, ok := err.(validator.ValidationErrors); ok {
+			trans := translator(c)
 			var ve []ValidationError
 			for _, e := range errs {
 				ve = append(ve, ValidationError{
-					Field:   e.Field(),
-					Message: messageForTag(e),
+					Field:   fieldPath(e),
+					Message: translate(e, trans),
 				})
 			}
 			return ve, fmt.Errorf("Validation failed")
@@ -39,18 +44,11 @@ func ValidateRequest(c *fiber.Ctx, input any) ([]ValidationError, error) {
 	return nil, nil
 }
 
-func messageForTag(fieldErr validator.FieldError) string {
-	switch fieldErr.Tag() {
-	case "required":
-		return "field is required"
-	case "email":
-		return "format is not valid"
-	case "min":
-		return fmt.Sprintf("minimum length must be %s", fieldErr.Param())
-	case "max":
-		return fmt.Sprintf("maximum length must be  %s", fieldErr.Param())
-	default:
-		return "Invalid value"
+// Namespace of the field without the name of the request struct
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, ok := strings.Cut(fieldErr.Namespace(), ".")
+	if !ok {
+		return fieldErr.Field()
 	}
-
+	return path
 }
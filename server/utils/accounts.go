@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"errors"
+
+	accountModels "github.com/niko-2609/tracker-expense/models/account"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Signed effect of a transaction on its account balance, the `in` leg of a transfer adds to it
+const balanceDeltaSQL = "CASE WHEN t.txn_type = 'income' OR t.transfer_direction = 'in' THEN t.amount ELSE -t.amount END"
+
+// Get the user's default account, the oldest one. A cash account is
+// created for users that have none yet so every transaction has an account.
+// A unique index allows one such account per user, so concurrent first
+// requests get the same one.
+func GetDefaultAccount(db *gorm.DB, userID uint) (*accountModels.Account, error) {
+	var account accountModels.Account
+	err := db.Where("user_id = ?", userID).Order("id").First(&account).Error
+	if err == nil {
+		return &account, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	account = accountModels.Account{
+		UserID:      userID,
+		Name:        "Main",
+		AccountType: accountModels.AccountTypeCash,
+		Currency:    "USD",
+		AutoCreated: true,
+	}
+	err = db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "user_id"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "auto_created AND deleted_at IS NULL"}}},
+		DoNothing:   true,
+	}).Create(&account).Error
+	if err != nil {
+		return nil, err
+	}
+	if account.ID != 0 {
+		return &account, nil
+	}
+
+	// Another request created it first
+	account = accountModels.Account{}
+	if err := db.Where("user_id = ?", userID).Order("id").First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Get an account by id if it belongs to the user
+func GetUserAccount(db *gorm.DB, userID, accountID uint) (*accountModels.Account, error) {
+	var account accountModels.Account
+	if err := db.Where("id = ? AND user_id = ?", accountID, userID).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// Get all accounts of the user with their current balance
+func GetAccountBalances(db *gorm.DB, userID uint) ([]accountModels.AccountBalance, error) {
+	var balances []accountModels.AccountBalance
+	err := db.Table("accounts a").
+		Select("a.id, a.name, a.account_type, a.currency, a.opening_balance, a.opening_balance + COALESCE(SUM("+balanceDeltaSQL+"), 0) AS balance").
+		Joins("LEFT JOIN transactions t ON t.account_id = a.id AND t.deleted_at IS NULL").
+		Where("a.user_id = ? AND a.deleted_at IS NULL", userID).
+		Group("a.id").
+		Order("a.id").
+		Scan(&balances).Error
+	return balances, err
+}
+
+// Get the balance of an account at the end of every day or month with activity.
+// `interval` is either `day` or `month`.
+func GetBalanceHistory(db *gorm.DB, account *accountModels.Account, interval string) ([]accountModels.BalancePoint, error) {
+	if interval != "day" && interval != "month" {
+		interval = "month"
+	}
+
+	historyQuery := `
+	SELECT period AS date, ? + SUM(delta) OVER (ORDER BY period) AS balance
+	FROM (
+		SELECT DATE_TRUNC(?, t.txn_date) AS period, SUM(` + balanceDeltaSQL + `) AS delta
+		FROM transactions t
+		WHERE t.account_id = ? AND t.deleted_at IS NULL
+		GROUP BY 1
+	) periods
+	ORDER BY period
+	`
+	var history []accountModels.BalancePoint
+	err := db.Raw(historyQuery, account.OpeningBalance, interval, account.ID).Scan(&history).Error
+	return history, err
+}
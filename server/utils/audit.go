@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+)
+
+type auditActorKey struct{}
+
+// Who made a change, carried in the DB context so helpers can audit without
+// knowing about the request
+type AuditActor struct {
+	UserID    uint
+	RequestID string
+	IP        string
+}
+
+// Context for DB work done on behalf of the request, use it with
+// `database.DB.WithContext` so changes to transactions are attributed.
+func AuditContext(c *fiber.Ctx) context.Context {
+	actor := AuditActor{IP: c.IP()}
+	if userID, err := GetUserId(c); err == nil {
+		actor.UserID = userID
+	}
+	if requestID, ok := c.Locals("requestid").(string); ok {
+		actor.RequestID = requestID
+	}
+	return context.WithValue(c.UserContext(), auditActorKey{}, actor)
+}
+
+// Load a transaction with its tags, split lines and shares, deleted ones included
+func LoadTransactionSnapshot(db *gorm.DB, transactionID uint) (*transactionModels.Transaction, error) {
+	var transaction transactionModels.Transaction
+	if err := db.Unscoped().Preload("Tags").Preload("Splits").Preload("Shares", func(db *gorm.DB) *gorm.DB {
+		return db.Order("user_id")
+	}).First(&transaction, transactionID).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// Record the creation of a transaction
+func AuditTransactionCreate(db *gorm.DB, transactionID uint) error {
+	after, err := LoadTransactionSnapshot(db, transactionID)
+	if err != nil {
+		return err
+	}
+	return RecordTransactionAudit(db, transactionModels.AuditCreate, nil, after)
+}
+
+// Run `change` and record how it changed the transaction
+func AuditTransactionChange(db *gorm.DB, transactionID uint, action string, change func() error) error {
+	before, err := LoadTransactionSnapshot(db, transactionID)
+	if err != nil {
+		return err
+	}
+	if err := change(); err != nil {
+		return err
+	}
+	after, err := LoadTransactionSnapshot(db, transactionID)
+	if err != nil {
+		return err
+	}
+	return RecordTransactionAudit(db, action, before, after)
+}
+
+// Write an audit record with the fields that differ between the snapshots.
+// Either snapshot may be nil. Updates that change nothing are not recorded.
+func RecordTransactionAudit(db *gorm.DB, action string, before, after *transactionModels.Transaction) error {
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+	if subject == nil {
+		return errors.New("audit needs a transaction snapshot")
+	}
+
+	changes := diffTransactionFields(auditFields(before), auditFields(after))
+	if len(changes) == 0 && action == transactionModels.AuditUpdate {
+		return nil
+	}
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	audit := transactionModels.TransactionAudit{
+		TransactionID: subject.ID,
+		UserID:        subject.UserID,
+		ActorID:       subject.UserID,
+		Action:        action,
+		Changes:       encoded,
+	}
+	if actor, ok := db.Statement.Context.Value(auditActorKey{}).(AuditActor); ok {
+		if actor.UserID != 0 {
+			audit.ActorID = actor.UserID
+		}
+		audit.RequestID = actor.RequestID
+		audit.IP = actor.IP
+	}
+	return db.Create(&audit).Error
+}
+
+// Audited fields of a transaction, empty for a nil snapshot. Tags, split
+// lines and shares are reduced to their content so re-created rows do not show up as changes.
+func auditFields(t *transactionModels.Transaction) map[string]any {
+	if t == nil {
+		return map[string]any{}
+	}
+
+	tags := make([]string, 0, len(t.Tags))
+	for _, tag := range t.Tags {
+		tags = append(tags, tag.Name)
+	}
+	sort.Strings(tags)
+
+	splits := make([]map[string]any, 0, len(t.Splits))
+	for _, split := range t.Splits {
+		splits = append(splits, map[string]any{
+			"category_id": split.CategoryID,
+			"amount":      split.Amount,
+			"memo":        split.Memo,
+		})
+	}
+
+	shares := make([]map[string]any, 0, len(t.Shares))
+	for _, share := range t.Shares {
+		shares = append(shares, map[string]any{
+			"user_id": share.UserID,
+			"amount":  share.Amount,
+		})
+	}
+
+	fields := map[string]any{
+		"account_id":         t.AccountID,
+		"name":               t.Name,
+		"amount":             t.Amount,
+		"txn_type":           t.TxnType,
+		"frequency":          t.Frequency,
+		"category_id":        t.CategoryID,
+		"txn_date":           t.TxnDate.UTC().Format(time.RFC3339),
+		"description":        t.Description,
+		"transfer_direction": t.TransferDirection,
+		"tags":               tags,
+		"splits":             splits,
+		"shares":             shares,
+		"transfer_id":        nil,
+		"goal_id":            nil,
+		"deleted_at":         nil,
+	}
+	if t.TransferID != nil {
+		fields["transfer_id"] = *t.TransferID
+	}
+	if t.GoalID != nil {
+		fields["goal_id"] = *t.GoalID
+	}
+	if t.DeletedAt.Valid {
+		fields["deleted_at"] = t.DeletedAt.Time.UTC().Format(time.RFC3339)
+	}
+	return fields
+}
+
+func diffTransactionFields(before, after map[string]any) map[string]transactionModels.FieldChange {
+	changes := make(map[string]transactionModels.FieldChange)
+	for name := range before {
+		if _, ok := after[name]; !ok {
+			changes[name] = transactionModels.FieldChange{Before: before[name]}
+		}
+	}
+	for name, value := range after {
+		previous, ok := before[name]
+		if ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		if !ok && isEmptyAuditValue(value) {
+			continue
+		}
+		changes[name] = transactionModels.FieldChange{Before: previous, After: value}
+	}
+	return changes
+}
+
+// Skip unset fields when a transaction is created
+func isEmptyAuditValue(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
@@ -22,6 +22,7 @@ func CreateJWTToken(userData models.UserCache) (string, error) {
 	// Populate the claims
 	claims["user_id"] = userData.ID
 	claims["user_email"] = userData.Email
+	claims["locale"] = userData.Locale
 	claims["exp"] = time.Now().Add(time.Hour * 3).Unix()
 
 	// Sign the token with signing method defined above and our signing key
@@ -63,6 +64,21 @@ func GetUserId(c *fiber.Ctx) (uint, error) {
 	return uint(userID), nil
 }
 
+// Preferred locale of the user from the JWT claims, empty when the request
+// has no token or the user did not pick one
+func GetUserLocale(c *fiber.Ctx) string {
+	user, ok := c.Locals("user").(*jwt.Token)
+	if !ok {
+		return ""
+	}
+	claims, ok := user.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	locale, _ := claims["locale"].(string)
+	return locale
+}
+
 // Extract user name from password
 func ExtractUserName(email string) string {
 	parts := strings.Split(email, "@")
@@ -1,16 +1,59 @@
 package utils
 
-import "github.com/niko-2609/tracker-expense/database"
+import (
+	"context"
+	"log/slog"
+	"time"
 
-func UpdateDashboardMetrics(userID uint) error {
+	"github.com/niko-2609/tracker-expense/database"
+	"github.com/niko-2609/tracker-expense/pkg/metrics"
+	"github.com/niko-2609/tracker-expense/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"gorm.io/gorm"
+)
+
+// Expense amounts per category line for a user (two `?`, both user_id).
+// A transaction with split lines yields one row per split, otherwise
+// a single row with its own category. Shared expenses only count the
+// user's share, spread over the lines. Use this for any category aggregation.
+const ExpenseCategoryLinesQuery = `
+	SELECT t.id AS transaction_id, t.txn_date,
+		COALESCE(s.category_id, t.category_id) AS category_id,
+		COALESCE(s.amount, t.amount) * COALESCE(es.amount / NULLIF(t.amount, 0), 1) AS amount
+	FROM transactions t
+	LEFT JOIN transaction_splits s ON s.transaction_id = t.id
+	LEFT JOIN expense_shares es ON es.transaction_id = t.id AND es.user_id = ?
+	WHERE t.txn_type = 'expense' AND t.deleted_at IS NULL
+		AND (es.user_id IS NOT NULL OR (t.user_id = ? AND NOT EXISTS (
+			SELECT 1 FROM expense_shares x WHERE x.transaction_id = t.id)))
+	`
+
+func UpdateDashboardMetrics(ctx context.Context, userID uint) error {
+	ctx, span := tracing.Tracer.Start(ctx, "dashboard.update")
+	span.SetAttributes(attribute.Int64("user.id", int64(userID)))
+	defer span.End()
+
+	start := time.Now()
+	err := updateDashboardMetrics(database.DB.WithContext(ctx), userID)
+	metrics.ObserveDashboardUpdate(time.Since(start), err)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, "dashboard update failed")
+		slog.ErrorContext(ctx, "Cannot update dashboard metrics", "user_id", userID, "error", err)
+	}
+	return err
+}
+
+func updateDashboardMetrics(db *gorm.DB, userID uint) error {
 	// A. Total Income / Expense / Net Savings
 	totalQuery := `
     WITH totals AS (
         SELECT
-            SUM(CASE WHEN txn_type='income' THEN amount ELSE 0 END) AS total_income,
-            SUM(CASE WHEN txn_type='expense' THEN amount ELSE 0 END) AS total_expense
+            COALESCE(SUM(CASE WHEN txn_type='income' THEN amount ELSE 0 END), 0) AS total_income,
+            COALESCE(SUM(CASE WHEN txn_type='expense' THEN amount ELSE 0 END), 0) AS total_expense
         FROM transactions
-        WHERE user_id = ?
+        WHERE user_id = ? AND deleted_at IS NULL
     )
     INSERT INTO user_dashboard_metrics (user_id, total_income, total_expense, net_savings, updated_at)
     SELECT ?, total_income, total_expense, total_income - total_expense, NOW()
@@ -21,47 +64,93 @@ func UpdateDashboardMetrics(userID uint) error {
         net_savings = EXCLUDED.net_savings,
         updated_at = NOW();
     `
-	if err := database.DB.Exec(totalQuery, userID, userID).Error; err != nil {
+	if err := db.Exec(totalQuery, userID, userID).Error; err != nil {
 		return err
 	}
 
-	// B. Monthly Totals (for line chart)
+	// B. Monthly Totals (for line chart), transfers only move money between accounts
 	monthlyQuery := `
 	WITH monthly AS (
 	    SELECT
 	        TO_CHAR(DATE_TRUNC('month', txn_date), 'YYYY-MM') AS month,
 	        SUM(CASE WHEN txn_type='income' THEN amount ELSE -amount END) AS net
 	    FROM transactions
-	    WHERE user_id = ?
+	    WHERE user_id = ? AND txn_type IN ('income', 'expense') AND deleted_at IS NULL
 	    GROUP BY DATE_TRUNC('month', txn_date)
 	    ORDER BY month
 	)
 	UPDATE user_dashboard_metrics
-	SET monthly_totals = (SELECT jsonb_object_agg(month, net) FROM monthly)
+	SET monthly_totals = COALESCE((SELECT jsonb_object_agg(month, net) FROM monthly), '{}'::jsonb)
 	WHERE user_id = ?;
 	`
-	if err := database.DB.Exec(monthlyQuery, userID, userID).Error; err != nil {
+	if err := db.Exec(monthlyQuery, userID, userID).Error; err != nil {
 		return err
 	}
 
 	// // C. Top 5 Expense Categories (for pie chart)
+	// Split transactions count per split line instead of the parent's category,
+	// shared expenses count the user's share.
 	topCatQuery := `
 	UPDATE user_dashboard_metrics
 	SET top_expense_categories = (
     	SELECT jsonb_agg(jsonb_build_object('category', category, 'amount', amount))
     		FROM (
-        	SELECT c.name AS category, SUM(t.amount) AS amount
-        	FROM transactions t
-        	JOIN categories c ON t.category_id = c.id
-        	WHERE t.user_id = ? AND t.txn_type = 'expense'
+        	SELECT c.name AS category, SUM(l.amount) AS amount
+        	FROM (` + ExpenseCategoryLinesQuery + `) l
+        	JOIN categories c ON l.category_id = c.id
         	GROUP BY c.id, c.name
-        	ORDER BY SUM(t.amount) DESC
+        	ORDER BY SUM(l.amount) DESC
         	LIMIT 5
     	) sub
 	)
 	WHERE user_id = ?;
 	`
-	if err := database.DB.Exec(topCatQuery, userID, userID).Error; err != nil {
+	if err := db.Exec(topCatQuery, userID, userID, userID).Error; err != nil {
+		return err
+	}
+
+	// D. Expense by tag (for spending by tag report)
+	tagQuery := `
+	UPDATE user_dashboard_metrics
+	SET expense_by_tag = COALESCE((
+		SELECT jsonb_agg(jsonb_build_object('tag', tag, 'amount', amount))
+			FROM (
+			SELECT tg.name AS tag, SUM(t.amount) AS amount
+			FROM transactions t
+			JOIN transaction_tags tt ON tt.transaction_id = t.id
+			JOIN tags tg ON tg.id = tt.tag_id
+			WHERE t.user_id = ? AND t.txn_type = 'expense' AND t.deleted_at IS NULL
+			GROUP BY tg.id, tg.name
+			ORDER BY SUM(t.amount) DESC
+		) sub
+	), '[]'::jsonb)
+	WHERE user_id = ?;
+	`
+	if err := db.Exec(tagQuery, userID, userID).Error; err != nil {
+		return err
+	}
+
+	// E. Net worth, opening balances plus all activity of the user's accounts.
+	// Currencies are never added up: `net_worth` is in the currency of the
+	// default account, the oldest one, and every currency has its own total.
+	netWorthQuery := `
+	WITH balances AS (
+		SELECT a.currency, SUM(a.opening_balance + COALESCE((
+			SELECT SUM(` + balanceDeltaSQL + `) FROM transactions t WHERE t.account_id = a.id AND t.deleted_at IS NULL
+		), 0)) AS balance
+		FROM accounts a
+		WHERE a.user_id = ? AND a.deleted_at IS NULL
+		GROUP BY a.currency
+	)
+	UPDATE user_dashboard_metrics
+	SET net_worth_by_currency = COALESCE((SELECT jsonb_object_agg(currency, balance) FROM balances), '{}'::jsonb),
+		net_worth = COALESCE((
+			SELECT balance FROM balances WHERE currency = (
+				SELECT currency FROM accounts WHERE user_id = ? AND deleted_at IS NULL ORDER BY id LIMIT 1)
+		), 0)
+	WHERE user_id = ?;
+	`
+	if err := db.Exec(netWorthQuery, userID, userID, userID).Error; err != nil {
 		return err
 	}
 
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"time"
+	"unicode"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+)
+
+// Thresholds for two transactions to count as duplicates
+const (
+	duplicateDateWindowDays = 3    // `txn_date` is a DATE, compared in whole days
+	duplicateAmountRatio    = 0.01 // Amounts within 1% of each other
+	duplicateMinTolerance   = 0.01
+	duplicateNameSimilarity = 0.8
+)
+
+// Find transactions of the ledger that look like the same entry: similar amount,
+// nearby date and similar name. `excludeID` skips the transaction itself.
+func FindDuplicateCandidates(db *gorm.DB, ledgerID uint, name string, amount float64, date time.Time, excludeID uint) ([]transactionModels.Transaction, error) {
+	tolerance := math.Max(amount*duplicateAmountRatio, duplicateMinTolerance)
+
+	var nearby []transactionModels.Transaction
+	err := db.Where("ledger_id = ? AND id <> ? AND txn_type <> ?", ledgerID, excludeID, transactionModels.TxnTypeTransfer).
+		Where("amount BETWEEN ? AND ?", amount-tolerance, amount+tolerance).
+		Where("txn_date BETWEEN ? AND ?", date.AddDate(0, 0, -duplicateDateWindowDays).Format(time.DateOnly), date.AddDate(0, 0, duplicateDateWindowDays).Format(time.DateOnly)).
+		Order("txn_date DESC").
+		Find(&nearby).Error
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]transactionModels.Transaction, 0, len(nearby))
+	for _, transaction := range nearby {
+		if NameSimilarity(name, transaction.Name) >= duplicateNameSimilarity {
+			candidates = append(candidates, transaction)
+		}
+	}
+	return candidates, nil
+}
+
+// Find suspected duplicate pairs within each of the user's ledgers,
+// leaving out pairs that were dismissed.
+func FindDuplicatePairs(db *gorm.DB, userID uint) ([]transactionModels.DuplicatePair, error) {
+	type pairIDs struct {
+		FirstID  uint
+		SecondID uint
+	}
+
+	// Amount and date are compared in SQL, names in Go
+	var ids []pairIDs
+	err := db.Raw(`
+	SELECT a.id AS first_id, b.id AS second_id
+	FROM transactions a
+	JOIN transactions b ON b.ledger_id = a.ledger_id AND b.id > a.id
+	WHERE a.ledger_id IN (SELECT ledger_id FROM ledger_members WHERE user_id = ?)
+		AND a.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND a.txn_type <> 'transfer' AND b.txn_type <> 'transfer'
+		AND ABS(a.amount - b.amount) <= GREATEST(a.amount * ?, ?)
+		AND ABS(a.txn_date - b.txn_date) <= ?
+		AND NOT EXISTS (
+			SELECT 1 FROM duplicate_dismissals d
+			WHERE d.first_id = a.id AND d.second_id = b.id
+		)
+	ORDER BY a.id, b.id
+	`, userID, duplicateAmountRatio, duplicateMinTolerance, duplicateDateWindowDays).Scan(&ids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return []transactionModels.DuplicatePair{}, nil
+	}
+
+	transactionIDs := make([]uint, 0, len(ids)*2)
+	for _, pair := range ids {
+		transactionIDs = append(transactionIDs, pair.FirstID, pair.SecondID)
+	}
+	var transactions []transactionModels.Transaction
+	if err := db.Where("id IN ?", transactionIDs).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]transactionModels.Transaction, len(transactions))
+	for _, transaction := range transactions {
+		byID[transaction.ID] = transaction
+	}
+
+	pairs := make([]transactionModels.DuplicatePair, 0, len(ids))
+	for _, pair := range ids {
+		first, second := byID[pair.FirstID], byID[pair.SecondID]
+		similarity := NameSimilarity(first.Name, second.Name)
+		if similarity < duplicateNameSimilarity {
+			continue
+		}
+		pairs = append(pairs, transactionModels.DuplicatePair{
+			First:      first,
+			Second:     second,
+			Similarity: math.Round(similarity*100) / 100,
+		})
+	}
+	return pairs, nil
+}
+
+// Similarity of two names between 0 and 1, based on the edit distance of
+// their normalized forms. Case, punctuation and spacing are ignored.
+func NameSimilarity(a, b string) float64 {
+	ra, rb := []rune(normalizeName(a)), []rune(normalizeName(b))
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func normalizeName(name string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
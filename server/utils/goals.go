@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	goalModels "github.com/niko-2609/tracker-expense/models/goal"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+)
+
+// Number of completed months used for the savings rate
+const savingsRateMonths = 3
+
+// Get the progress of a goal, the contribution needed to meet its deadline and the
+// completion date projected from the user's recent savings rate.
+func GetGoalProgress(db *gorm.DB, goal *goalModels.SavingsGoal) (*goalModels.GoalProgress, error) {
+	// Incomes and transfers in add to the goal, anything linked that takes
+	// money out of an account, e.g. an income later changed to an expense, is
+	// subtracted
+	var saved float64
+	if err := db.Table("transactions t").
+		Select("COALESCE(SUM("+balanceDeltaSQL+"), 0)").
+		Where("t.goal_id = ? AND t.user_id = ? AND t.deleted_at IS NULL", goal.ID, goal.UserID).
+		Scan(&saved).Error; err != nil {
+		return nil, err
+	}
+
+	rate, err := GetMonthlySavingsRate(db, goal.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	remaining := math.Max(goal.TargetAmount-saved, 0)
+	progress := &goalModels.GoalProgress{
+		Goal:               *goal,
+		SavedAmount:        saved,
+		RemainingAmount:    remaining,
+		PercentComplete:    math.Min(math.Round(saved/goal.TargetAmount*10000)/100, 100),
+		MonthlySavingsRate: rate,
+	}
+
+	if goal.Deadline != nil {
+		months := monthsUntil(now, *goal.Deadline)
+		needed := math.Round(remaining/float64(months)*100) / 100
+		progress.MonthlyNeeded = &needed
+	}
+
+	switch {
+	case remaining == 0:
+		progress.ProjectedCompletion = &now
+	case rate > 0:
+		projected := now.AddDate(0, int(math.Ceil(remaining/rate)), 0)
+		progress.ProjectedCompletion = &projected
+	}
+
+	return progress, nil
+}
+
+// Get the average net savings of the last completed months from `monthly_totals`
+func GetMonthlySavingsRate(db *gorm.DB, userID uint) (float64, error) {
+	var metrics transactionModels.DashboardMetrics
+	if err := db.Select("monthly_totals").Where("user_id = ?", userID).First(&metrics).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	monthlyTotals := map[string]float64{}
+	if len(metrics.MonthlyTotals) > 0 {
+		if err := json.Unmarshal(metrics.MonthlyTotals, &monthlyTotals); err != nil {
+			return 0, err
+		}
+	}
+
+	// Months without transactions count as zero savings
+	var total float64
+	month := time.Now().AddDate(0, 0, 1-time.Now().Day())
+	for i := 0; i < savingsRateMonths; i++ {
+		month = month.AddDate(0, -1, 0)
+		total += monthlyTotals[month.Format("2006-01")]
+	}
+	return math.Round(total/savingsRateMonths*100) / 100, nil
+}
+
+// Whole months left until the deadline, at least one
+func monthsUntil(from, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	if months < 1 {
+		return 1
+	}
+	return months
+}
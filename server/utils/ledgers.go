@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"errors"
+
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// Returned when the user is not a member of the ledger.
+	ErrLedgerNotFound = errors.New("ledger not found")
+	// Returned when the user's role in the ledger does not allow the action.
+	ErrLedgerForbidden = errors.New("ledger role does not allow this action")
+)
+
+// Rank of each role, a role can do everything lower ranked ones can
+var roleRank = map[string]int{
+	ledgerModels.RoleViewer: 1,
+	ledgerModels.RoleEditor: 2,
+	ledgerModels.RoleOwner:  3,
+}
+
+// Get the user's personal ledger, it is created for users that have none yet
+func GetPersonalLedger(db *gorm.DB, userID uint) (*ledgerModels.Ledger, error) {
+	var ledger ledgerModels.Ledger
+	err := db.Where("owner_id = ? AND personal", userID).First(&ledger).Error
+	if err == nil {
+		return &ledger, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	ledger = ledgerModels.Ledger{
+		Name:     "Personal",
+		OwnerID:  userID,
+		Personal: true,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "owner_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "personal AND deleted_at IS NULL"}}},
+			DoNothing:   true,
+		}).Create(&ledger).Error
+		if err != nil || ledger.ID == 0 {
+			return err
+		}
+		return tx.Create(&ledgerModels.LedgerMember{
+			LedgerID: ledger.ID,
+			UserID:   userID,
+			Role:     ledgerModels.RoleOwner,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if ledger.ID != 0 {
+		return &ledger, nil
+	}
+
+	// Another request created it first
+	ledger = ledgerModels.Ledger{}
+	if err := db.Where("owner_id = ? AND personal", userID).First(&ledger).Error; err != nil {
+		return nil, err
+	}
+	return &ledger, nil
+}
+
+// Role of the user in the ledger, ErrLedgerNotFound when not a member
+func GetLedgerRole(db *gorm.DB, ledgerID, userID uint) (string, error) {
+	var member ledgerModels.LedgerMember
+	err := db.Joins("JOIN ledgers ON ledgers.id = ledger_members.ledger_id AND ledgers.deleted_at IS NULL").
+		Where("ledger_members.ledger_id = ? AND ledger_members.user_id = ?", ledgerID, userID).
+		First(&member).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", ErrLedgerNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return member.Role, nil
+}
+
+// The user must be a member of the ledger with at least the `required` role
+func CheckLedgerRole(db *gorm.DB, ledgerID, userID uint, required string) error {
+	role, err := GetLedgerRole(db, ledgerID, userID)
+	if err != nil {
+		return err
+	}
+	if roleRank[role] < roleRank[required] {
+		return ErrLedgerForbidden
+	}
+	return nil
+}
+
+// Pick the ledger for a new transaction: the requested one when the user has
+// the `required` role in it, otherwise the user's personal ledger.
+func ResolveLedger(db *gorm.DB, userID, ledgerID uint, required string) (uint, error) {
+	if ledgerID == 0 {
+		ledger, err := GetPersonalLedger(db, userID)
+		if err != nil {
+			return 0, err
+		}
+		return ledger.ID, nil
+	}
+	if err := CheckLedgerRole(db, ledgerID, userID, required); err != nil {
+		return 0, err
+	}
+	return ledgerID, nil
+}
+
+// Scope to transactions in ledgers the user is a member of, deleted ledgers
+// are left out
+func MemberLedgers(userID uint) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("transactions.ledger_id IN (?)", db.Session(&gorm.Session{NewDB: true}).
+			Model(&ledgerModels.LedgerMember{}).
+			Select("ledger_members.ledger_id").
+			Joins("JOIN ledgers ON ledgers.id = ledger_members.ledger_id AND ledgers.deleted_at IS NULL").
+			Where("ledger_members.user_id = ?", userID))
+	}
+}
+
+// Find a transaction in one of the user's ledgers where the user has at least
+// the `required` role. Returns gorm.ErrRecordNotFound for transactions outside
+// the user's ledgers. `db` may carry extra conditions such as Unscoped or a lock.
+func FindLedgerTransaction(db *gorm.DB, userID uint, transactionID any, required string) (*transactionModels.Transaction, error) {
+	var transaction transactionModels.Transaction
+	if err := db.Scopes(MemberLedgers(userID)).Where("transactions.id = ?", transactionID).First(&transaction).Error; err != nil {
+		return nil, err
+	}
+	if err := CheckLedgerRole(db.Session(&gorm.Session{NewDB: true}), transaction.LedgerID, userID, required); err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"errors"
+	"slices"
+
+	ruleModels "github.com/niko-2609/tracker-expense/models/rule"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"github.com/niko-2609/tracker-expense/pkg/rules"
+	"gorm.io/gorm"
+)
+
+// Returned by ApplyRules when the user has no enabled rules.
+var ErrNoRules = errors.New("no enabled rules")
+
+type ApplyRulesOptions struct {
+	DryRun            bool
+	TransactionIDs    []uint // Limit to these transactions, all when empty
+	OnlyUncategorized bool
+}
+
+// Get the user's enabled categorization rules in the order they run
+func GetUserRules(db *gorm.DB, userID uint) ([]ruleModels.CategorizationRule, error) {
+	var rules []ruleModels.CategorizationRule
+	err := db.Where("user_id = ? AND enabled", userID).Order("priority, id").Find(&rules).Error
+	return rules, err
+}
+
+// Run the user's rules over existing transactions and return what changes.
+// Transfers are skipped. Unless `DryRun` is set the changes are saved.
+func ApplyRules(db *gorm.DB, userID uint, opts ApplyRulesOptions) ([]ruleModels.RuleChange, error) {
+	ruleset, err := GetUserRules(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(ruleset) == 0 {
+		return nil, ErrNoRules
+	}
+
+	query := db.Where("user_id = ? AND txn_type <> ?", userID, transactionModels.TxnTypeTransfer).Preload("Tags").Order("id")
+	if len(opts.TransactionIDs) > 0 {
+		query = query.Where("id IN ?", opts.TransactionIDs)
+	}
+	if opts.OnlyUncategorized {
+		query = query.Where("category_id IS NULL OR category_id = 0")
+	}
+
+	var transactions []transactionModels.Transaction
+	if err := query.Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	changes := make([]ruleModels.RuleChange, 0)
+	for _, transaction := range transactions {
+		outcome := rules.Evaluate(ruleset, rules.Candidate{
+			Name:        transaction.Name,
+			Description: transaction.Description,
+			Amount:      transaction.Amount,
+			TxnType:     transaction.TxnType,
+		})
+
+		before := ruleModels.RuleTarget{
+			Name:       transaction.Name,
+			CategoryID: transaction.CategoryID,
+			Tags:       make([]string, 0, len(transaction.Tags)),
+		}
+		for _, tag := range transaction.Tags {
+			before.Tags = append(before.Tags, tag.Name)
+		}
+
+		after := ruleModels.RuleTarget{
+			Name:       before.Name,
+			CategoryID: before.CategoryID,
+			Tags:       NormalizeTagNames(append(slices.Clone(before.Tags), outcome.Tags...)),
+		}
+		if outcome.Name != nil {
+			after.Name = *outcome.Name
+		}
+		if outcome.CategoryID != nil {
+			after.CategoryID = *outcome.CategoryID
+		}
+
+		if after.Name == before.Name && after.CategoryID == before.CategoryID && len(after.Tags) == len(before.Tags) {
+			continue
+		}
+		changes = append(changes, ruleModels.RuleChange{
+			TransactionID: transaction.ID,
+			RuleIDs:       outcome.RuleIDs,
+			Before:        before,
+			After:         after,
+		})
+	}
+
+	if opts.DryRun || len(changes) == 0 {
+		return changes, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, change := range changes {
+			patchMap := map[string]any{"name": change.After.Name, "version": BumpVersion()}
+			if change.After.CategoryID != 0 {
+				patchMap["category_id"] = change.After.CategoryID
+			}
+			err := AuditTransactionChange(tx, change.TransactionID, transactionModels.AuditUpdate, func() error {
+				if err := tx.Model(&transactionModels.Transaction{}).Where("id = ? AND user_id = ?", change.TransactionID, userID).Updates(patchMap).Error; err != nil {
+					return err
+				}
+				if len(change.After.Tags) != len(change.Before.Tags) {
+					transaction := transactionModels.Transaction{UserID: userID}
+					transaction.ID = change.TransactionID
+					return SetTransactionTags(tx, &transaction, change.After.Tags)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
@@ -0,0 +1,394 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+)
+
+// Returned when the members or values of a share request cannot divide the expense.
+var ErrInvalidShares = errors.New("invalid shares")
+
+// Divide `amount` between the members by `method`. Shares are rounded to cents,
+// leftover cents go to the members with the largest remainders so the shares
+// always add up to the amount.
+func ComputeShares(method string, amount float64, members []transactionModels.ShareMemberRequest) ([]transactionModels.ExpenseShare, error) {
+	seen := make(map[uint]bool, len(members))
+	for _, member := range members {
+		if seen[member.UserID] {
+			return nil, fmt.Errorf("%w: user %d is listed twice", ErrInvalidShares, member.UserID)
+		}
+		seen[member.UserID] = true
+	}
+
+	total := toCents(amount)
+	weights := make([]float64, len(members))
+	var sum float64
+	for i, member := range members {
+		weights[i] = member.Value
+		if method == transactionModels.ShareEqual {
+			weights[i] = 1
+		}
+		sum += weights[i]
+	}
+
+	var cents []int64
+	switch method {
+	case transactionModels.ShareExact:
+		cents = make([]int64, len(members))
+		var exact int64
+		for i, member := range members {
+			cents[i] = toCents(member.Value)
+			exact += cents[i]
+		}
+		if exact != total {
+			return nil, fmt.Errorf("%w: exact amounts must add up to the expense", ErrInvalidShares)
+		}
+	case transactionModels.SharePercentage:
+		if math.Abs(sum-100) > 0.001 {
+			return nil, fmt.Errorf("%w: percentages must add up to 100", ErrInvalidShares)
+		}
+		cents = allocateCents(total, weights)
+	case transactionModels.ShareEqual, transactionModels.ShareShares:
+		if sum <= 0 {
+			return nil, fmt.Errorf("%w: at least one member needs a share", ErrInvalidShares)
+		}
+		cents = allocateCents(total, weights)
+	default:
+		return nil, fmt.Errorf("%w: unknown method %q", ErrInvalidShares, method)
+	}
+
+	shares := make([]transactionModels.ExpenseShare, len(members))
+	for i, member := range members {
+		shares[i] = transactionModels.ExpenseShare{
+			UserID: member.UserID,
+			Amount: float64(cents[i]) / 100,
+		}
+	}
+	return shares, nil
+}
+
+// Scale shares to a new expense amount keeping their proportions
+func RescaleShares(shares []transactionModels.ExpenseShare, amount float64) []transactionModels.ExpenseShare {
+	weights := make([]float64, len(shares))
+	for i, share := range shares {
+		weights[i] = share.Amount
+	}
+	cents := allocateCents(toCents(amount), weights)
+
+	rescaled := make([]transactionModels.ExpenseShare, len(shares))
+	for i, share := range shares {
+		rescaled[i] = transactionModels.ExpenseShare{
+			TransactionID: share.TransactionID,
+			UserID:        share.UserID,
+			Amount:        float64(cents[i]) / 100,
+		}
+	}
+	return rescaled
+}
+
+// Replace the shares of an expense, no shares removes the split
+func SetExpenseShares(db *gorm.DB, transactionID uint, shares []transactionModels.ExpenseShare) error {
+	if err := db.Where("transaction_id = ?", transactionID).Delete(&transactionModels.ExpenseShare{}).Error; err != nil {
+		return err
+	}
+	if len(shares) == 0 {
+		return nil
+	}
+	for i := range shares {
+		shares[i].TransactionID = transactionID
+	}
+	return db.Create(&shares).Error
+}
+
+// Users holding a share of the expense
+func ExpenseShareUserIDs(db *gorm.DB, transactionID any) ([]uint, error) {
+	var userIDs []uint
+	err := db.Model(&transactionModels.ExpenseShare{}).
+		Where("transaction_id = ?", transactionID).
+		Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// Balances of everyone taking part in the ledger's shared expenses. The payer
+// of an expense is the transaction's user, settlements move money between members.
+func GetLedgerBalances(db *gorm.DB, ledgerID uint) (*ledgerModels.LedgerBalances, error) {
+	type userAmount struct {
+		UserID uint
+		Amount float64
+	}
+	sums := func(query string) ([]userAmount, error) {
+		var rows []userAmount
+		err := db.Raw(query, ledgerID).Scan(&rows).Error
+		return rows, err
+	}
+
+	paid, err := sums(`
+		SELECT t.user_id, SUM(t.amount) AS amount
+		FROM transactions t
+		WHERE t.ledger_id = ? AND t.deleted_at IS NULL
+			AND EXISTS (SELECT 1 FROM expense_shares es WHERE es.transaction_id = t.id)
+		GROUP BY t.user_id`)
+	if err != nil {
+		return nil, err
+	}
+	owed, err := sums(`
+		SELECT es.user_id, SUM(es.amount) AS amount
+		FROM expense_shares es
+		JOIN transactions t ON t.id = es.transaction_id
+		WHERE t.ledger_id = ? AND t.deleted_at IS NULL
+		GROUP BY es.user_id`)
+	if err != nil {
+		return nil, err
+	}
+	sent, err := sums(`SELECT from_user_id AS user_id, SUM(amount) AS amount FROM settlements WHERE ledger_id = ? GROUP BY from_user_id`)
+	if err != nil {
+		return nil, err
+	}
+	received, err := sums(`SELECT to_user_id AS user_id, SUM(amount) AS amount FROM settlements WHERE ledger_id = ? GROUP BY to_user_id`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Current members first, then former members that still take part
+	var members []struct {
+		UserID   uint
+		Username string
+	}
+	err = db.Raw(`
+		SELECT u.id AS user_id, u.username
+		FROM users u
+		LEFT JOIN ledger_members m ON m.user_id = u.id AND m.ledger_id = ?
+		WHERE m.user_id IS NOT NULL OR u.id IN (
+			SELECT t.user_id FROM transactions t JOIN expense_shares es ON es.transaction_id = t.id WHERE t.ledger_id = ?
+			UNION SELECT es.user_id FROM expense_shares es JOIN transactions t ON t.id = es.transaction_id WHERE t.ledger_id = ?
+			UNION SELECT from_user_id FROM settlements WHERE ledger_id = ?
+			UNION SELECT to_user_id FROM settlements WHERE ledger_id = ?
+		)
+		ORDER BY m.user_id IS NULL, u.id`, ledgerID, ledgerID, ledgerID, ledgerID, ledgerID).Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]ledgerModels.MemberBalance, 0, len(members))
+	index := make(map[uint]int, len(members))
+	for _, member := range members {
+		index[member.UserID] = len(balances)
+		balances = append(balances, ledgerModels.MemberBalance{UserID: member.UserID, Username: member.Username})
+	}
+	net := make([]int64, len(balances))
+	apply := func(rows []userAmount, sign int64, field func(*ledgerModels.MemberBalance) *float64) {
+		for _, row := range rows {
+			i, ok := index[row.UserID]
+			if !ok {
+				continue
+			}
+			if field != nil {
+				*field(&balances[i]) = row.Amount
+			}
+			net[i] += sign * toCents(row.Amount)
+		}
+	}
+	apply(paid, 1, func(b *ledgerModels.MemberBalance) *float64 { return &b.Paid })
+	apply(owed, -1, func(b *ledgerModels.MemberBalance) *float64 { return &b.Owed })
+	apply(sent, 1, nil)
+	apply(received, -1, nil)
+
+	for i := range balances {
+		balances[i].Net = float64(net[i]) / 100
+	}
+
+	return &ledgerModels.LedgerBalances{
+		Balances: balances,
+		SettleUp: SimplifyDebts(balances),
+	}, nil
+}
+
+// Open balances up to which SimplifyDebts finds the fewest payments, the
+// search takes 2^n steps
+const maxExactSettleUp = 16
+
+// Reduce balances to the fewest payments that settle everyone. Members are
+// split into as many groups whose balances add up to zero as possible, each
+// group settles on its own with one payment less than it has members.
+//
+// With more than `maxExactSettleUp` open balances the members are settled as
+// one group instead, which still needs at most one payment less than there
+// are open balances but may need more than the fewest.
+func SimplifyDebts(balances []ledgerModels.MemberBalance) []ledgerModels.SettleUpPayment {
+	var open []debtParty
+	for _, balance := range balances {
+		if cents := toCents(balance.Net); cents != 0 {
+			open = append(open, debtParty{balance.UserID, cents})
+		}
+	}
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].userID < open[j].userID
+	})
+
+	payments := make([]ledgerModels.SettleUpPayment, 0)
+	if len(open) > maxExactSettleUp {
+		return settleGroup(payments, open)
+	}
+	for _, group := range zeroSumGroups(open) {
+		payments = settleGroup(payments, group)
+	}
+	return payments
+}
+
+// Member with an open balance in cents, positive when owed money
+type debtParty struct {
+	userID uint
+	cents  int64
+}
+
+// Split the parties into the most groups whose balances add up to zero.
+// best[mask] is the most groups the parties in `mask` split into: the best
+// of leaving out any one party, plus one when the parties add up to zero.
+func zeroSumGroups(parties []debtParty) [][]debtParty {
+	n := len(parties)
+	if n == 0 {
+		return nil
+	}
+	full := 1<<n - 1
+	sums := make([]int64, full+1)
+	best := make([]int, full+1)
+	for mask := 1; mask <= full; mask++ {
+		low := bits.TrailingZeros(uint(mask))
+		rest := mask &^ (1 << low)
+		sums[mask] = sums[rest] + parties[low].cents
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 {
+				best[mask] = max(best[mask], best[mask&^(1<<i)])
+			}
+		}
+		if sums[mask] == 0 {
+			best[mask]++
+		}
+	}
+
+	// Remove parties one by one keeping the most groups, groups close
+	// whenever the remaining parties add up to zero
+	var groups [][]debtParty
+	var group []debtParty
+	for mask := full; mask != 0; {
+		groups, group = closeGroup(groups, group, sums[mask] == 0)
+		target := best[mask]
+		if sums[mask] == 0 {
+			target--
+		}
+		for i := 0; i < n; i++ {
+			if mask&(1<<i) != 0 && best[mask&^(1<<i)] == target {
+				group = append(group, parties[i])
+				mask &^= 1 << i
+				break
+			}
+		}
+	}
+	groups, _ = closeGroup(groups, group, true)
+	return groups
+}
+
+func closeGroup(groups [][]debtParty, group []debtParty, done bool) ([][]debtParty, []debtParty) {
+	if !done || len(group) == 0 {
+		return groups, group
+	}
+	return append(groups, group), nil
+}
+
+// Settle a group whose balances add up to zero: the member who owes the most
+// pays the member who is owed the most until everyone is settled. Needs one
+// payment less than the group has members at most.
+func settleGroup(payments []ledgerModels.SettleUpPayment, group []debtParty) []ledgerModels.SettleUpPayment {
+	var creditors, debtors []debtParty
+	for _, party := range group {
+		if party.cents > 0 {
+			creditors = append(creditors, party)
+		} else {
+			debtors = append(debtors, debtParty{party.userID, -party.cents})
+		}
+	}
+	byAmount := func(parties []debtParty) func(i, j int) bool {
+		return func(i, j int) bool {
+			if parties[i].cents != parties[j].cents {
+				return parties[i].cents > parties[j].cents
+			}
+			return parties[i].userID < parties[j].userID
+		}
+	}
+	sort.Slice(creditors, byAmount(creditors))
+	sort.Slice(debtors, byAmount(debtors))
+
+	for c, d := 0, 0; c < len(creditors) && d < len(debtors); {
+		amount := min(creditors[c].cents, debtors[d].cents)
+		payments = append(payments, ledgerModels.SettleUpPayment{
+			FromUserID: debtors[d].userID,
+			ToUserID:   creditors[c].userID,
+			Amount:     float64(amount) / 100,
+		})
+		creditors[c].cents -= amount
+		debtors[d].cents -= amount
+		if creditors[c].cents == 0 {
+			c++
+		}
+		if debtors[d].cents == 0 {
+			d++
+		}
+	}
+	return payments
+}
+
+// Open balance of a user in the ledger, zero when settled
+func GetMemberBalance(db *gorm.DB, ledgerID, userID uint) (float64, error) {
+	balances, err := GetLedgerBalances(db, ledgerID)
+	if err != nil {
+		return 0, err
+	}
+	for _, balance := range balances.Balances {
+		if balance.UserID == userID {
+			return balance.Net, nil
+		}
+	}
+	return 0, nil
+}
+
+// Split `total` cents by weight using the largest remainder method
+func allocateCents(total int64, weights []float64) []int64 {
+	var sum float64
+	for _, weight := range weights {
+		sum += weight
+	}
+	cents := make([]int64, len(weights))
+	if sum <= 0 {
+		return cents
+	}
+
+	remainders := make([]int, len(weights))
+	fractions := make([]float64, len(weights))
+	var allocated int64
+	for i, weight := range weights {
+		exact := float64(total) * weight / sum
+		cents[i] = int64(math.Floor(exact))
+		fractions[i] = exact - float64(cents[i])
+		remainders[i] = i
+		allocated += cents[i]
+	}
+	sort.SliceStable(remainders, func(a, b int) bool {
+		return fractions[remainders[a]] > fractions[remainders[b]]
+	})
+	for i := 0; allocated < total; i++ {
+		cents[remainders[i%len(remainders)]]++
+		allocated++
+	}
+	return cents
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+)
+
+func TestComputeShares(t *testing.T) {
+	members := func(values ...float64) []transactionModels.ShareMemberRequest {
+		requests := make([]transactionModels.ShareMemberRequest, len(values))
+		for i, value := range values {
+			requests[i] = transactionModels.ShareMemberRequest{UserID: uint(i + 1), Value: value}
+		}
+		return requests
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		amount  float64
+		members []transactionModels.ShareMemberRequest
+		want    []float64
+		wantErr bool
+	}{
+		{"equal", transactionModels.ShareEqual, 90, members(0, 0, 0), []float64{30, 30, 30}, false},
+		{"equal with leftover cents", transactionModels.ShareEqual, 100, members(0, 0, 0), []float64{33.34, 33.33, 33.33}, false},
+		{"equal one cent", transactionModels.ShareEqual, 0.01, members(0, 0), []float64{0.01, 0}, false},
+		{"exact", transactionModels.ShareExact, 50, members(20.5, 29.5), []float64{20.5, 29.5}, false},
+		{"exact not adding up", transactionModels.ShareExact, 50, members(20, 20), nil, true},
+		{"percentage", transactionModels.SharePercentage, 10, members(33.3, 33.3, 33.4), []float64{3.33, 3.33, 3.34}, false},
+		{"percentage not adding up", transactionModels.SharePercentage, 10, members(50, 40), nil, true},
+		{"shares", transactionModels.ShareShares, 10, members(1, 2), []float64{3.33, 6.67}, false},
+		{"no shares", transactionModels.ShareShares, 10, members(0, 0), nil, true},
+		{"unknown method", "halves", 10, members(1, 1), nil, true},
+		{"member twice", transactionModels.ShareEqual, 10, append(members(0), members(0)...), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := ComputeShares(tt.method, tt.amount, tt.members)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidShares) {
+					t.Fatalf("err = %v, want ErrInvalidShares", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var sum int64
+			for i, share := range shares {
+				if share.Amount != tt.want[i] {
+					t.Errorf("share %d = %v, want %v", i, share.Amount, tt.want[i])
+				}
+				sum += toCents(share.Amount)
+			}
+			if sum != toCents(tt.amount) {
+				t.Errorf("shares add up to %d cents, want %d", sum, toCents(tt.amount))
+			}
+		})
+	}
+}
+
+func TestAllocateCents(t *testing.T) {
+	tests := []struct {
+		name    string
+		total   int64
+		weights []float64
+	}{
+		{"even", 300, []float64{1, 1, 1}},
+		{"thirds", 100, []float64{1, 1, 1}},
+		{"sevenths", 1000, []float64{1, 1, 1, 1, 1, 1, 1}},
+		{"uneven", 12345, []float64{0.1, 2.7, 13, 0.33}},
+		{"one cent", 1, []float64{1, 1, 1}},
+		{"zero weight", 999, []float64{0, 3, 5}},
+		{"negative amount", -1001, []float64{1, 2}},
+		{"many members", 1_000_001, []float64{3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cents := allocateCents(tt.total, tt.weights)
+			var sum int64
+			for i, c := range cents {
+				sum += c
+				if tt.weights[i] == 0 && c != 0 {
+					t.Errorf("member %d without weight got %d cents", i, c)
+				}
+			}
+			if sum != tt.total {
+				t.Errorf("allocated %d cents, want %d", sum, tt.total)
+			}
+		})
+	}
+
+	if cents := allocateCents(100, []float64{0, 0}); cents[0] != 0 || cents[1] != 0 {
+		t.Errorf("without weights = %v, want nothing allocated", cents)
+	}
+}
+
+func TestSimplifyDebts(t *testing.T) {
+	tests := []struct {
+		name         string
+		nets         []float64 // Of users 1, 2, ...
+		wantPayments int
+	}{
+		{"settled", []float64{0, 0}, 0},
+		{"one debt", []float64{10, -10}, 1},
+		{"one creditor", []float64{20, -5, -15}, 2},
+		{"no zero sum pair", []float64{7, 5, -6, -6}, 3},
+		// Paying the largest amounts first misses the pair 7, -7 and needs 5
+		{"hidden pair", []float64{-9, 7, -2, 5, 6, -7}, 4},
+		{"independent pairs", []float64{4, 6, -4, -6}, 2},
+		{"pair and triple", []float64{3, -3, 5, 2, -7}, 3},
+		{"cents", []float64{0.01, 0.02, -0.03}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments := SimplifyDebts(memberBalances(tt.nets))
+			if len(payments) != tt.wantPayments {
+				t.Errorf("got %d payments %v, want %d", len(payments), payments, tt.wantPayments)
+			}
+			assertSettled(t, tt.nets, payments)
+		})
+	}
+
+	// Too many open balances for the exact search, still settles everyone
+	nets := make([]float64, maxExactSettleUp+4)
+	for i := range nets {
+		nets[i] = float64(i + 1)
+		if i%2 == 1 {
+			nets[i] = -float64(i)
+		}
+	}
+	payments := SimplifyDebts(memberBalances(nets))
+	if len(payments) >= len(nets) {
+		t.Errorf("got %d payments for %d open balances", len(payments), len(nets))
+	}
+	assertSettled(t, nets, payments)
+}
+
+func memberBalances(nets []float64) []ledgerModels.MemberBalance {
+	balances := make([]ledgerModels.MemberBalance, len(nets))
+	for i, net := range nets {
+		balances[i] = ledgerModels.MemberBalance{UserID: uint(i + 1), Net: net}
+	}
+	return balances
+}
+
+// Every balance is zero once the payments are made
+func assertSettled(t *testing.T, nets []float64, payments []ledgerModels.SettleUpPayment) {
+	t.Helper()
+	open := make(map[uint]int64, len(nets))
+	for i, net := range nets {
+		open[uint(i+1)] = toCents(net)
+	}
+	for _, payment := range payments {
+		if payment.Amount <= 0 {
+			t.Errorf("payment %v is not positive", payment)
+		}
+		open[payment.FromUserID] += toCents(payment.Amount)
+		open[payment.ToUserID] -= toCents(payment.Amount)
+	}
+	for userID, cents := range open {
+		if cents != 0 {
+			t.Errorf("user %d still has %d cents open", userID, cents)
+		}
+	}
+}
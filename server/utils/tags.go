@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"strings"
+
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Normalize tag names: trimmed, lower case and without duplicates
+func NormalizeTagNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		normalized = append(normalized, name)
+	}
+	return normalized
+}
+
+// Get the user's tags by name, creating the ones that do not exist yet
+func FindOrCreateTags(db *gorm.DB, userID uint, names []string) ([]transactionModels.Tag, error) {
+	names = NormalizeTagNames(names)
+	if len(names) == 0 {
+		return []transactionModels.Tag{}, nil
+	}
+
+	newTags := make([]transactionModels.Tag, 0, len(names))
+	for _, name := range names {
+		newTags = append(newTags, transactionModels.Tag{UserID: userID, Name: name})
+	}
+
+	// Insert missing tags, existing (user_id, name) pairs are left untouched
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newTags).Error; err != nil {
+		return nil, err
+	}
+
+	var tags []transactionModels.Tag
+	if err := db.Where("user_id = ? AND name IN ?", userID, names).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Replace the tags attached to a transaction with the given tag names
+func SetTransactionTags(db *gorm.DB, transaction *transactionModels.Transaction, names []string) error {
+	tags, err := FindOrCreateTags(db, transaction.UserID, names)
+	if err != nil {
+		return err
+	}
+	return db.Model(transaction).Association("Tags").Replace(tags)
+}
+
+// Scope to filter transactions by tag names.
+// With `matchAll` a transaction must carry every tag, otherwise any of them.
+// Tags belong to the owner of the transaction, so in shared ledgers the
+// tags of every member with these names match.
+func FilterByTags(names []string, matchAll bool) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		tagNames := NormalizeTagNames(names)
+		if len(tagNames) == 0 {
+			return db
+		}
+
+		sub := db.Session(&gorm.Session{NewDB: true}).
+			Table("transaction_tags tt").
+			Select("tt.transaction_id").
+			Joins("JOIN tags ON tags.id = tt.tag_id").
+			Where("tags.name IN ?", tagNames)
+		if matchAll {
+			sub = sub.Group("tt.transaction_id").Having("COUNT(DISTINCT tags.name) = ?", len(tagNames))
+		}
+		return db.Where("transactions.id IN (?)", sub)
+	}
+}
@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	accountModels "github.com/niko-2609/tracker-expense/models/account"
+	ledgerModels "github.com/niko-2609/tracker-expense/models/ledger"
+	transactionModels "github.com/niko-2609/tracker-expense/models/transaction"
+	"gorm.io/gorm"
+)
+
+var (
+	// Returned when an account does not belong to the user.
+	ErrAccountNotFound = errors.New("account not found")
+	// Returned when both accounts of a transfer do not share a currency.
+	ErrCurrencyMismatch = errors.New("transfer accounts must use the same currency")
+	// Returned when a transfer would move money into the account it leaves.
+	ErrSameAccount = errors.New("transfer accounts must be different")
+)
+
+// Create both legs of a transfer: an `out` leg on the source and an `in` leg
+// on the destination account, linked by a shared transfer id.
+func CreateTransfer(db *gorm.DB, userID uint, req *transactionModels.AddTransferRequest) ([]transactionModels.Transaction, error) {
+	if err := CheckTransferAccounts(db, userID, req.FromAccountID, req.ToAccountID); err != nil {
+		return nil, err
+	}
+
+	ledgerID, err := ResolveLedger(db, userID, req.LedgerID, ledgerModels.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	name := req.Name
+	if name == "" {
+		name = "Transfer"
+	}
+
+	transferID := uuid.NewString()
+	now := time.Now()
+	legs := []transactionModels.Transaction{
+		{
+			AccountID:         req.FromAccountID,
+			TransferDirection: transactionModels.TransferOut,
+		},
+		{
+			AccountID:         req.ToAccountID,
+			TransferDirection: transactionModels.TransferIn,
+		},
+	}
+	for i := range legs {
+		legs[i].UserID = userID
+		legs[i].LedgerID = ledgerID
+		legs[i].CreatedBy = userID
+		legs[i].Name = name
+		legs[i].Amount = req.Amount
+		legs[i].TxnType = transactionModels.TxnTypeTransfer
+		legs[i].Frequency = req.Frequency
+		legs[i].TxnDate = now
+		legs[i].Description = req.Description
+		legs[i].TransferID = &transferID
+	}
+
+	if err := db.Create(&legs).Error; err != nil {
+		return nil, err
+	}
+	for _, leg := range legs {
+		if err := AuditTransactionCreate(db, leg.ID); err != nil {
+			return nil, err
+		}
+	}
+	return legs, nil
+}
+
+// Both accounts must belong to the user, differ and share a currency
+func CheckTransferAccounts(db *gorm.DB, userID, fromAccountID, toAccountID uint) error {
+	if fromAccountID == toAccountID {
+		return ErrSameAccount
+	}
+
+	accounts := make([]*accountModels.Account, 0, 2)
+	for _, accountID := range []uint{fromAccountID, toAccountID} {
+		account, err := GetUserAccount(db, userID, accountID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrAccountNotFound
+		}
+		if err != nil {
+			return err
+		}
+		accounts = append(accounts, account)
+	}
+
+	if accounts[0].Currency != accounts[1].Currency {
+		return ErrCurrencyMismatch
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package utils
 import (
 	"net/mail"
 
-	"github.com/niko-2609/tracker-expense/database"
 	models "github.com/niko-2609/tracker-expense/models/auth"
 	"gorm.io/gorm"
 )
@@ -15,18 +14,18 @@ func IsEmail(email string) bool {
 }
 
 // Get user from DB by email
-func GetUserByEmail(email string) (*models.User, error) {
+func GetUserByEmail(db *gorm.DB, email string) (*models.User, error) {
 	var user models.User
-	if err := database.DB.Where(&models.User{Email: email}).First(&user).Error; err != nil {
+	if err := db.Where(&models.User{Email: email}).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
 // Get user by ID
-func GetUserByID(id uint) (*models.User, error) {
+func GetUserByID(db *gorm.DB, id uint) (*models.User, error) {
 	var user models.User
-	if err := database.DB.Model(&models.User{}).Where(&models.User{Model: gorm.Model{
+	if err := db.Model(&models.User{}).Where(&models.User{Model: gorm.Model{
 		ID: id,
 	}}).First(&user).Error; err != nil {
 		return nil, err
@@ -0,0 +1,12 @@
+package utils
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Column value that bumps the version of a transaction, add it to every
+// update so ETags handed out earlier stop matching.
+func BumpVersion() clause.Expr {
+	return gorm.Expr("version + 1")
+}